@@ -0,0 +1,203 @@
+package log
+
+import (
+	"context"
+	"path"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CallerFormat is how a CallerResolver prints the caller.
+type CallerFormat uint8
+
+// Caller formats.
+const (
+	// CallerShort prints the directory and the file, e.g. db/pool.go:12.
+	CallerShort CallerFormat = iota
+	// CallerFull prints the absolute path of the file, e.g. /src/app/internal/db/pool.go:12.
+	CallerFull
+	// CallerModule prints the path of the file within its module, e.g.
+	// internal/db/pool.go:12, or github.com/x/y/pool.go:12 out of the main module.
+	// The main package, whose import path says nothing of its directory, is
+	// printed as by CallerShort.
+	CallerModule
+	// CallerFunction prints the package and the function, e.g. db.(*Pool).Get.
+	CallerFunction
+)
+
+// helpers holds the functions and packages registered by RegisterHelper and
+// RegisterHelperPackage.
+var helpers sync.Map
+
+// RegisterHelper marks functions as logging helpers, as testing.T.Helper does:
+// the caller reported for an entry is the first frame out of go-logs and out of
+// helpers. A function is given as a func value or as its full name, e.g.
+// "github.com/acme/app/logx.Errorf".
+func RegisterHelper(fns ...interface{}) {
+	for _, fn := range fns {
+		name, ok := fn.(string)
+		if !ok {
+			v := reflect.ValueOf(fn)
+			if v.Kind() != reflect.Func {
+				continue
+			}
+			name = strings.TrimSuffix(runtime.FuncForPC(v.Pointer()).Name(), "-fm") // method values
+		}
+		helpers.Store(name, struct{}{})
+	}
+}
+
+// RegisterHelperPackage marks every function of the packages, given by import
+// path, as a logging helper, see RegisterHelper.
+func RegisterHelperPackage(paths ...string) {
+	for _, p := range paths {
+		helpers.Store(p, struct{}{})
+	}
+}
+
+func isHelper(function string) bool {
+	if _, ok := helpers.Load(function); ok {
+		return true
+	}
+	_, ok := helpers.Load(funcPackage(function))
+	return ok
+}
+
+// CallerOption is CallerResolver option.
+type CallerOption func(*callerResolver)
+
+// CallerSkip skips n more frames past the first one out of go-logs and the
+// helpers, for the wrappers that are not registered.
+func CallerSkip(n int) CallerOption {
+	return func(r *callerResolver) {
+		if n > 0 {
+			r.skip = n
+		}
+	}
+}
+
+// CallerWithFormat sets how the caller is printed, CallerShort by default.
+func CallerWithFormat(f CallerFormat) CallerOption {
+	return func(r *callerResolver) {
+		r.format = f
+	}
+}
+
+type callerResolver struct {
+	skip   int
+	format CallerFormat
+}
+
+// CallerResolver returns a Valuer describing the caller of the logger: the
+// first frame that belongs neither to go-logs nor to a registered helper, so
+// it does not depend on how many loggers wrap each other. Unlike Caller it
+// can be resolved at any depth.
+func CallerResolver(opts ...CallerOption) Valuer {
+	r := &callerResolver{}
+	for _, o := range opts {
+		o(r)
+	}
+	return func(context.Context) interface{} {
+		f, ok := r.caller()
+		if !ok {
+			return "???:0"
+		}
+		return r.print(f)
+	}
+}
+
+func (r *callerResolver) caller() (runtime.Frame, bool) {
+	var pcs [64]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs[:])])
+	skip := r.skip
+	for {
+		f, more := frames.Next()
+		if f.Function != "" && !internalFrame(f) && !isHelper(f.Function) {
+			if skip == 0 {
+				return f, true
+			}
+			skip--
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+func (r *callerResolver) print(f runtime.Frame) string {
+	switch r.format {
+	case CallerFull:
+		return f.File + ":" + strconv.Itoa(f.Line)
+	case CallerModule:
+		if file, ok := moduleFile(f); ok {
+			return file + ":" + strconv.Itoa(f.Line)
+		}
+	case CallerFunction:
+		return f.Function[strings.LastIndexByte(f.Function, '/')+1:]
+	}
+	return shortFile(f.File) + ":" + strconv.Itoa(f.Line)
+}
+
+// shortFile returns the last directory and the name of file.
+func shortFile(file string) string {
+	idx := strings.LastIndexByte(file, '/')
+	if idx == -1 {
+		return file
+	}
+	idx2 := strings.LastIndexByte(file[:idx], '/')
+	return file[idx2+1:]
+}
+
+// moduleFile returns the path of the file of f within its module, from the
+// import path of its package.
+func moduleFile(f runtime.Frame) (string, bool) {
+	pkg := strings.TrimSuffix(funcPackage(f.Function), "_test")
+	if pkg == "main" || pkg == "" {
+		return "", false
+	}
+	if mod := modulePath(pkg); mod != "" {
+		pkg = strings.TrimPrefix(strings.TrimPrefix(pkg, mod), "/")
+	}
+	return path.Join(pkg, path.Base(f.File)), true
+}
+
+var (
+	modulesOnce sync.Once
+	modules     []string
+)
+
+// modulePath returns the path of the module holding pkg, "" for the
+// standard library.
+func modulePath(pkg string) string {
+	modulesOnce.Do(func() {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		modules = append(modules, info.Main.Path)
+		for _, m := range info.Deps {
+			modules = append(modules, m.Path)
+		}
+	})
+	found := ""
+	for _, mod := range modules {
+		if mod != "" && len(mod) > len(found) && (pkg == mod || strings.HasPrefix(pkg, mod+"/")) {
+			found = mod
+		}
+	}
+	return found
+}
+
+// funcPackage returns the import path of the package of function, a name as
+// reported by runtime.Frame.
+func funcPackage(function string) string {
+	slash := strings.LastIndexByte(function, '/')
+	if dot := strings.IndexByte(function[slash+1:], '.'); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
@@ -0,0 +1,52 @@
+package log_test
+
+import (
+	"path"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	l "github.com/ysk229/go-logs"
+)
+
+// logVia stands for a wrapper around the logger, registered as a helper.
+func logVia(logger l.Logger) {
+	_ = logger.Log(l.LevelInfo, "msg", "helper")
+}
+
+// logUnregistered is a wrapper left to CallerSkip.
+func logUnregistered(logger l.Logger) {
+	_ = logger.Log(l.LevelInfo, "msg", "skip")
+}
+
+func TestCallerResolver(t *testing.T) {
+	l.RegisterHelper(logVia)
+	rec := &recorder{}
+	caller := func(opts ...l.CallerOption) interface{} {
+		logger := l.With(l.With(rec, "caller", l.CallerResolver(opts...)), "k", "v")
+		logVia(logger)
+		return rec.entries[len(rec.entries)-1][2]
+	}
+	_, file, line, _ := runtime.Caller(0)
+	want := ":" + strconv.Itoa(line-3)
+	short := path.Base(path.Dir(file)) + "/caller_test.go"
+
+	assert.Equal(t, short+want, caller())
+	assert.Equal(t, file+want, caller(l.CallerWithFormat(l.CallerFull)))
+	assert.Equal(t, "caller_test.go"+want, caller(l.CallerWithFormat(l.CallerModule)))
+	assert.Equal(t, "go-logs_test.TestCallerResolver.func1", caller(l.CallerWithFormat(l.CallerFunction)))
+
+	// the frames of unregistered wrappers are skipped by CallerSkip
+	logger := l.With(rec, "caller", l.CallerResolver(l.CallerSkip(1), l.CallerWithFormat(l.CallerFull)))
+	logUnregistered(logger)
+	_, _, line, _ = runtime.Caller(0)
+	assert.Equal(t, file+":"+strconv.Itoa(line-1), rec.entries[len(rec.entries)-1][2])
+
+	// registered by name, DefaultCaller resolves the same way
+	l.RegisterHelper("github.com/ysk229/go-logs_test.logUnregistered")
+	logUnregistered(l.With(rec, "caller", l.DefaultCaller))
+	_, _, line, _ = runtime.Caller(0)
+	assert.Equal(t, short+":"+strconv.Itoa(line-1), rec.entries[len(rec.entries)-1][2])
+}
@@ -1,5 +1,15 @@
 package config
 
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	log "github.com/ysk229/go-logs"
+)
+
 // yaml
 // log:
 // both: all #all ,file console,console is dufault
@@ -12,15 +22,236 @@ package config
 // max_age: 80  # 日志文件存储最大天数
 
 type Config struct {
-	Type   string // log type std zap logrus,zap is default
-	Both   string // all ,file console,console is default
-	Level  string // info ,error ...
-	Format string // json text
-	File   FileConf
+	Type   string     `yaml:"type" toml:"type" json:"type"`       // log type std zap logrus,zap is default
+	Both   string     `yaml:"both" toml:"both" json:"both"`       // all ,file console,console is default
+	Level  Level      `yaml:"level" toml:"level" json:"level"`    // info ,error ...
+	Levels string     `yaml:"levels" toml:"levels" json:"levels"` // per logger name, e.g. db=debug,http=warn,*=info
+	Format string     `yaml:"format" toml:"format" json:"format"` // json text
+	File   FileConf   `yaml:"file" toml:"file" json:"file"`
+	Sinks  []Sink     `yaml:"sinks" toml:"sinks" json:"sinks"` // replaces Both/Format/File when set
+	PII    PIIConf    `yaml:"pii" toml:"pii" json:"pii"`
+	Sample SampleConf `yaml:"sample" toml:"sample" json:"sample"`
+	Stack  StackConf  `yaml:"stack" toml:"stack" json:"stack"`
+	// Timestamp is how every backend timestamps entries.
+	Timestamp TimestampConf `yaml:"timestamp" toml:"timestamp" json:"timestamp"`
+	// StrictKeyvals makes Log return an error for malformed keyvals, see log.SetStrict.
+	StrictKeyvals bool `yaml:"strict_keyvals" toml:"strict_keyvals" json:"strict_keyvals"`
+	// DuplicateKeys resolves the keys an entry has more than once, see log.SetDuplicates.
+	DuplicateKeys string `yaml:"duplicate_keys" toml:"duplicate_keys" json:"duplicate_keys"` // keep_all last_wins first_wins suffix, keep_all is default
+}
+
+// SampleConf caps repeated messages: per tick, the first entries of a message
+// and level are logged, then every Thereafter-th one. See log.NewSampler.
+type SampleConf struct {
+	Tick       string          `yaml:"tick" toml:"tick" json:"tick"`                   // e.g. 1s, 1s is default
+	First      int             `yaml:"first" toml:"first" json:"first"`                // every level, 0 disables
+	Thereafter int             `yaml:"thereafter" toml:"thereafter" json:"thereafter"` // 0 drops the rest
+	Levels     map[string]Rate `yaml:"levels" toml:"levels" json:"levels"`             // per level, e.g. debug: {first: 10}
+}
+
+// Rate is the sampling of one level.
+type Rate struct {
+	First      int `yaml:"first" toml:"first" json:"first"`
+	Thereafter int `yaml:"thereafter" toml:"thereafter" json:"thereafter"`
+}
+
+// Enabled reports whether any level is sampled.
+func (s SampleConf) Enabled() bool {
+	return s.First > 0 || len(s.Levels) > 0
+}
+
+// TimestampConf is the layout, time zone and clock of the timestamps of
+// entries, see log.TimeFormat.
+type TimestampConf struct {
+	Format   string    `yaml:"format" toml:"format" json:"format"`          // Go layout, or RFC3339Nano unix unix_ms unix_nano, 2006-01-02.15:04:05.000000 is default
+	TimeZone string    `yaml:"time_zone" toml:"time_zone" json:"time_zone"` // e.g. Europe/Paris, local time is default
+	UTC      bool      `yaml:"utc" toml:"utc" json:"utc"`                   // overrides time_zone
+	Clock    log.Clock `yaml:"-" toml:"-" json:"-"`                         // set in code, e.g. a fixed time in tests
+}
+
+// TimeFormat returns the log.TimeFormat of c.Timestamp, c may be nil. An
+// unknown time zone is ignored, Validate reports it.
+func (c *Config) TimeFormat() log.TimeFormat {
+	if c == nil {
+		return log.TimeFormat{}
+	}
+	t := c.Timestamp
+	f := log.TimeFormat{Layout: t.Format, Clock: t.Clock}
+	switch {
+	case t.UTC:
+		f.Location = time.UTC
+	case t.TimeZone != "":
+		f.Location, _ = time.LoadLocation(t.TimeZone)
+	}
+	return f
+}
+
+// StackNone is the StackConf level attaching no stack trace.
+const StackNone = "none"
+
+// StackConf attaches the stack trace of the caller under the "stack" key to
+// the entries of a level and above, whatever the backend. See log.NewStackTracer.
+type StackConf struct {
+	Level    string `yaml:"level" toml:"level" json:"level"`          // minimum level, error is default, none disables
+	Depth    int    `yaml:"depth" toml:"depth" json:"depth"`          // frames kept, 32 is default
+	Internal bool   `yaml:"internal" toml:"internal" json:"internal"` // keep the runtime and go-logs frames
+}
+
+// StackTracer returns logger adding the stack traces of c.Stack, to the
+// entries at error and above by default, or logger itself when the level is
+// StackNone. c may be nil.
+func (c *Config) StackTracer(logger log.Logger) log.Logger {
+	var stack StackConf
+	if c != nil {
+		stack = c.Stack
+	}
+	if strings.EqualFold(stack.Level, StackNone) {
+		return logger
+	}
+	opts := []log.StackOption{log.StackDepth(stack.Depth), log.StackInternal(stack.Internal)}
+	if stack.Level != "" {
+		opts = append(opts, log.StackLevel(log.ParseLevel(stack.Level)))
+	}
+	return log.NewStackTracer(logger, opts...)
 }
+
+// PIIConf enables the detectors scrubbing personal data from messages and
+// field values, see log.FilterPII.
+type PIIConf struct {
+	Detectors []string `yaml:"detectors" toml:"detectors" json:"detectors"` // e.g. email, credit_card, none when empty
+	Redaction string   `yaml:"redaction" toml:"redaction" json:"redaction"` // mask hash drop, mask is default
+	Salt      string   `yaml:"salt" toml:"salt" json:"salt"`                // salt of hash
+}
+
+// Level is a level name such as "info". Decoding it from a file or the
+// environment fails for unknown names, the empty Level selects the default.
+type Level string
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (l *Level) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	if s != "" {
+		if _, err := log.ParseLevelE(s); err != nil {
+			return err
+		}
+	}
+	*l = Level(strings.ToLower(s))
+	return nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler so errors carry the line.
+func (l *Level) UnmarshalYAML(value *yaml.Node) error {
+	if err := l.UnmarshalText([]byte(value.Value)); err != nil {
+		return fmt.Errorf("line %d: level: %w", value.Line, err)
+	}
+	return nil
+}
+
+// Sink is one output of the logger with its own format and level range.
+type Sink struct {
+	Name     string   `yaml:"name" toml:"name" json:"name"`
+	Type     string   `yaml:"type" toml:"type" json:"type"`                // console stdout stderr file
+	Format   string   `yaml:"format" toml:"format" json:"format"`          // json text
+	Level    string   `yaml:"level" toml:"level" json:"level"`             // minimum level, empty for all
+	MaxLevel string   `yaml:"max_level" toml:"max_level" json:"max_level"` // maximum level, empty for all
+	File     FileConf `yaml:"file" toml:"file" json:"file"`                // used by file sinks
+}
+
 type FileConf struct {
-	Mode   string // size data
-	Path   string // file path
-	MaxAge int    // file maxAge
-	Size   int    // file size （M）
+	Name   string `yaml:"name" toml:"name" json:"name"`          // file name, sinks default to <sink name>.log
+	Mode   string `yaml:"mode" toml:"mode" json:"mode"`          // size data
+	Path   string `yaml:"path" toml:"path" json:"path"`          // file path
+	MaxAge int    `yaml:"max_age" toml:"max_age" json:"max_age"` // file maxAge
+	Size   int    `yaml:"size" toml:"size" json:"size"`          // file size （M）
+}
+
+// Default returns a Config holding the documented defaults.
+func Default() *Config {
+	return &Config{
+		Type:   "zap",
+		Both:   "console",
+		Level:  "warn",
+		Format: "text",
+		File: FileConf{
+			Mode:   "size",
+			Path:   "./logs",
+			MaxAge: 90,
+			Size:   30,
+		},
+	}
+}
+
+// NameLevels parses Levels into a map of logger name to level, "*" is
+// returned as the empty name.
+func (c *Config) NameLevels() (map[string]string, error) {
+	levels := make(map[string]string)
+	for _, item := range strings.Split(c.Levels, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		i := strings.IndexByte(item, '=')
+		if i <= 0 {
+			return nil, fmt.Errorf("config: levels: %q is not name=level", item)
+		}
+		name, level := strings.TrimSpace(item[:i]), strings.TrimSpace(item[i+1:])
+		if name == "*" {
+			name = ""
+		}
+		levels[name] = level
+	}
+	return levels, nil
+}
+
+// EffectiveSinks returns c.Sinks, or when it is empty the sinks described by
+// Both, Format and File: info.log gets debug to warn, error.log error and above.
+// File settings a file sink leaves empty are taken from c.File.
+func (c *Config) EffectiveSinks() []Sink {
+	if len(c.Sinks) > 0 {
+		sinks := make([]Sink, len(c.Sinks))
+		for i, s := range c.Sinks {
+			if s.Type == "file" {
+				s.File = s.File.inherit(c.File)
+				if s.File.Name == "" {
+					name := s.Name
+					if name == "" {
+						name = "log"
+					}
+					s.File.Name = name + ".log"
+				}
+			}
+			sinks[i] = s
+		}
+		return sinks
+	}
+	info, errs := c.File, c.File
+	info.Name, errs.Name = "info.log", "error.log"
+	files := []Sink{
+		{Name: "info", Type: "file", Format: c.Format, MaxLevel: "warn", File: info},
+		{Name: "error", Type: "file", Format: c.Format, Level: "error", File: errs},
+	}
+	console := Sink{Name: "console", Type: "console", Format: c.Format}
+	switch c.Both {
+	case "all":
+		return append(files, console)
+	case "file":
+		return files
+	}
+	return []Sink{console}
+}
+
+func (f FileConf) inherit(parent FileConf) FileConf {
+	if f.Mode == "" {
+		f.Mode = parent.Mode
+	}
+	if f.Path == "" {
+		f.Path = parent.Path
+	}
+	if f.MaxAge == 0 {
+		f.MaxAge = parent.MaxAge
+	}
+	if f.Size == 0 {
+		f.Size = parent.Size
+	}
+	return f
 }
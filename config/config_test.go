@@ -0,0 +1,67 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	log "github.com/ysk229/go-logs"
+)
+
+func TestEffectiveSinks(t *testing.T) {
+	file := FileConf{Path: "/var/log/app", Size: 10}
+	info := Sink{Name: "info", Type: "file", Format: "json", MaxLevel: "warn",
+		File: FileConf{Name: "info.log", Path: "/var/log/app", Size: 10}}
+	errs := Sink{Name: "error", Type: "file", Format: "json", Level: "error",
+		File: FileConf{Name: "error.log", Path: "/var/log/app", Size: 10}}
+	console := Sink{Name: "console", Type: "console", Format: "json"}
+
+	tests := map[string]struct {
+		conf *Config
+		want []Sink
+	}{
+		"console": {
+			conf: &Config{Format: "json", File: file},
+			want: []Sink{console},
+		},
+		"file": {
+			conf: &Config{Both: "file", Format: "json", File: file},
+			want: []Sink{info, errs},
+		},
+		"all": {
+			conf: &Config{Both: "all", Format: "json", File: file},
+			want: []Sink{info, errs, console},
+		},
+		"sinks": {
+			conf: &Config{Both: "all", File: file, Sinks: []Sink{
+				{Type: "stderr", Level: "debug"},
+				{Name: "audit", Type: "file", Format: "json", File: FileConf{Path: "/audit"}},
+				{Type: "file", File: FileConf{Name: "all.log", Mode: "date"}},
+			}},
+			want: []Sink{
+				{Type: "stderr", Level: "debug"},
+				{Name: "audit", Type: "file", Format: "json",
+					File: FileConf{Name: "audit.log", Path: "/audit", Size: 10}},
+				{Type: "file", File: FileConf{Name: "all.log", Mode: "date", Path: "/var/log/app", Size: 10}},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.conf.EffectiveSinks())
+		})
+	}
+}
+
+func TestTimeFormat(t *testing.T) {
+	var nilConf *Config
+	assert.Equal(t, log.TimeFormat{}, nilConf.TimeFormat())
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	assert.NoError(t, err)
+	conf := &Config{Timestamp: TimestampConf{Format: "unix", TimeZone: "Europe/Paris"}}
+	assert.Equal(t, log.TimeFormat{Layout: "unix", Location: paris}, conf.TimeFormat())
+	conf.Timestamp.UTC = true
+	assert.Equal(t, time.UTC, conf.TimeFormat().Location)
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultEnvPrefix is the environment variable prefix used when none is given.
+const DefaultEnvPrefix = "GOLOGS"
+
+type envVar struct {
+	name string
+	set  func(v string) error
+}
+
+func (c *Config) envVars() []envVar {
+	return []envVar{
+		{"TYPE", setString(&c.Type)},
+		{"BOTH", setString(&c.Both)},
+		{"LEVEL", setText(&c.Level)},
+		{"LEVELS", setString(&c.Levels)},
+		{"FORMAT", setString(&c.Format)},
+		{"FILE_MODE", setString(&c.File.Mode)},
+		{"FILE_PATH", setString(&c.File.Path)},
+		{"FILE_SIZE", setInt(&c.File.Size)},
+		{"FILE_MAX_AGE", setInt(&c.File.MaxAge)},
+		{"PII_DETECTORS", setList(&c.PII.Detectors)},
+		{"PII_REDACTION", setString(&c.PII.Redaction)},
+		{"PII_SALT", setString(&c.PII.Salt)},
+		{"SAMPLE_TICK", setString(&c.Sample.Tick)},
+		{"SAMPLE_FIRST", setInt(&c.Sample.First)},
+		{"SAMPLE_THEREAFTER", setInt(&c.Sample.Thereafter)},
+		{"STACK_LEVEL", setString(&c.Stack.Level)},
+		{"STACK_DEPTH", setInt(&c.Stack.Depth)},
+		{"STACK_INTERNAL", setBool(&c.Stack.Internal)},
+		{"TIMESTAMP_FORMAT", setString(&c.Timestamp.Format)},
+		{"TIMESTAMP_TIME_ZONE", setString(&c.Timestamp.TimeZone)},
+		{"TIMESTAMP_UTC", setBool(&c.Timestamp.UTC)},
+		{"STRICT_KEYVALS", setBool(&c.StrictKeyvals)},
+		{"DUPLICATE_KEYS", setString(&c.DuplicateKeys)},
+	}
+}
+
+// ApplyEnv overlays the PREFIX_* environment variables onto c, e.g.
+// GOLOGS_LEVEL or GOLOGS_FILE_PATH. Unset variables leave the field untouched,
+// an empty prefix means DefaultEnvPrefix.
+func (c *Config) ApplyEnv(prefix string) error {
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	prefix = strings.TrimSuffix(strings.ToUpper(prefix), "_") + "_"
+	for _, e := range c.envVars() {
+		v, ok := os.LookupEnv(prefix + e.name)
+		if !ok {
+			continue
+		}
+		if err := e.set(strings.TrimSpace(v)); err != nil {
+			return fmt.Errorf("config: %s%s: %w", prefix, e.name, err)
+		}
+	}
+	return nil
+}
+
+// LoadWithEnv builds a Config by merging, in order: Default, the file at path
+// (skipped when path is empty) and the environment variables read by ApplyEnv.
+func LoadWithEnv(path, prefix string) (*Config, error) {
+	conf := Default()
+	if path != "" {
+		var err error
+		if conf, err = Load(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := conf.ApplyEnv(prefix); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+func setString(p *string) func(string) error {
+	return func(v string) error {
+		*p = v
+		return nil
+	}
+}
+
+func setList(p *[]string) func(string) error {
+	return func(v string) error {
+		*p = nil
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*p = append(*p, item)
+			}
+		}
+		return nil
+	}
+}
+
+func setText(u encoding.TextUnmarshaler) func(string) error {
+	return func(v string) error {
+		return u.UnmarshalText([]byte(v))
+	}
+}
+
+func setInt(p *int) func(string) error {
+	return func(v string) error {
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		*p = i
+		return nil
+	}
+}
+
+func setBool(p *bool) func(string) error {
+	return func(v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		*p = b
+		return nil
+	}
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv("GOLOGS_TYPE", "logrus")
+	t.Setenv("GOLOGS_BOTH", "all")
+	t.Setenv("GOLOGS_LEVEL", "debug")
+	t.Setenv("GOLOGS_FORMAT", "json")
+	t.Setenv("GOLOGS_FILE_MODE", "date")
+	t.Setenv("GOLOGS_FILE_PATH", "/var/log/app")
+	t.Setenv("GOLOGS_FILE_SIZE", "10")
+	t.Setenv("GOLOGS_FILE_MAX_AGE", "3")
+	t.Setenv("GOLOGS_PII_DETECTORS", "email, jwt")
+	t.Setenv("GOLOGS_STRICT_KEYVALS", "true")
+	t.Setenv("GOLOGS_STACK_LEVEL", "warn")
+	t.Setenv("GOLOGS_STACK_DEPTH", "8")
+	t.Setenv("GOLOGS_TIMESTAMP_FORMAT", "unix")
+	t.Setenv("GOLOGS_TIMESTAMP_UTC", "true")
+	t.Setenv("APP_LEVEL", "error")
+
+	conf := Default()
+	assert.NoError(t, conf.ApplyEnv(""))
+	assert.Equal(t, &Config{
+		Type: "logrus", Both: "all", Level: "debug", Format: "json",
+		File:      FileConf{Mode: "date", Path: "/var/log/app", Size: 10, MaxAge: 3},
+		PII:       PIIConf{Detectors: []string{"email", "jwt"}},
+		Stack:     StackConf{Level: "warn", Depth: 8},
+		Timestamp: TimestampConf{Format: "unix", UTC: true},
+
+		StrictKeyvals: true,
+	}, conf)
+
+	conf = Default()
+	assert.NoError(t, conf.ApplyEnv("app_"))
+	assert.Equal(t, Level("error"), conf.Level)
+	assert.Equal(t, "zap", conf.Type)
+
+	t.Setenv("GOLOGS_FILE_SIZE", "big")
+	err := Default().ApplyEnv(DefaultEnvPrefix)
+	assert.Contains(t, err.Error(), "GOLOGS_FILE_SIZE")
+
+	t.Setenv("APP_LEVEL", "loud")
+	assert.ErrorContains(t, Default().ApplyEnv("APP"), `APP_LEVEL: log: unrecognized level "loud"`)
+}
+
+func TestLoadWithEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("log:\n  level: info\n  format: json\n"), 0o600))
+	t.Setenv("SVC_LEVEL", "error")
+
+	conf, err := LoadWithEnv(path, "SVC")
+	assert.NoError(t, err)
+	assert.Equal(t, Level("error"), conf.Level)
+	assert.Equal(t, "json", conf.Format)
+	assert.Equal(t, "console", conf.Both)
+
+	conf, err = LoadWithEnv("", "SVC")
+	assert.NoError(t, err)
+	assert.Equal(t, Level("error"), conf.Level)
+	assert.Equal(t, "text", conf.Format)
+}
+
+func TestEnvVarsDocumented(t *testing.T) {
+	readme, err := os.ReadFile(filepath.Join("..", "README.md"))
+	assert.NoError(t, err)
+	for _, e := range (&Config{}).envVars() {
+		assert.Contains(t, string(readme), "| `"+DefaultEnvPrefix+"_"+e.name+"` |", "README misses a variable ApplyEnv reads")
+	}
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported config file formats.
+const (
+	YAML = "yaml"
+	TOML = "toml"
+	JSON = "json"
+)
+
+var yamlLine = regexp.MustCompile(`line (\d+)`)
+
+// file is the on-disk layout, every setting lives under the "log" root.
+type file struct {
+	Log *Config `yaml:"log" toml:"log" json:"log"`
+}
+
+// ParseError reports malformed config input and where it was found.
+type ParseError struct {
+	File string // file name, empty when parsed from a reader
+	Line int    // 1-based line, 0 when unknown
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	name := e.File
+	if name == "" {
+		name = "<input>"
+	}
+	if e.Line > 0 {
+		return fmt.Sprintf("config: %s:%d: %v", name, e.Line, e.Err)
+	}
+	return fmt.Sprintf("config: %s: %v", name, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Load reads the config file at path. The format is picked from the file
+// extension (.yaml, .yml, .toml or .json), a trailing .example is ignored.
+func Load(path string) (*Config, error) {
+	format, err := formatOf(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	conf, err := Parse(f, format)
+	if err != nil {
+		var pe *ParseError
+		if errors.As(err, &pe) {
+			pe.File = path
+		}
+		return nil, err
+	}
+	return conf, nil
+}
+
+// Parse decodes a config in the given format from r on top of Default.
+func Parse(r io.Reader, format string) (*Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	conf := Default()
+	out := &file{Log: conf}
+	switch strings.ToLower(format) {
+	case YAML, "yml":
+		err = parseYAML(data, out)
+	case TOML:
+		err = parseTOML(data, out)
+	case JSON:
+		err = parseJSON(data, out)
+	default:
+		return nil, fmt.Errorf("config: unsupported format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if out.Log == nil {
+		out.Log = Default()
+	}
+	return out.Log, nil
+}
+
+func formatOf(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(strings.TrimSuffix(path, ".example"))) {
+	case ".yaml", ".yml":
+		return YAML, nil
+	case ".toml":
+		return TOML, nil
+	case ".json":
+		return JSON, nil
+	}
+	return "", fmt.Errorf("config: cannot detect format of %q", path)
+}
+
+func parseYAML(data []byte, out *file) error {
+	if err := yaml.Unmarshal(data, out); err != nil {
+		pe := &ParseError{Err: err}
+		if m := yamlLine.FindStringSubmatch(err.Error()); m != nil {
+			pe.Line, _ = strconv.Atoi(m[1])
+		}
+		return pe
+	}
+	return nil
+}
+
+func parseTOML(data []byte, out *file) error {
+	if _, err := toml.Decode(string(data), out); err != nil {
+		pe := &ParseError{Err: err}
+		var te toml.ParseError
+		if errors.As(err, &te) {
+			pe.Line = te.Position.Line
+		}
+		return pe
+	}
+	return nil
+}
+
+func parseJSON(data []byte, out *file) error {
+	if err := json.Unmarshal(data, out); err != nil {
+		pe := &ParseError{Err: err}
+		var se *json.SyntaxError
+		var te *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &se):
+			pe.Line = lineAt(data, se.Offset)
+		case errors.As(err, &te):
+			pe.Line = lineAt(data, te.Offset)
+		}
+		return pe
+	}
+	return nil
+}
+
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
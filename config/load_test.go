@@ -0,0 +1,150 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		format string
+		input  string
+		want   *Config
+	}{
+		"yaml": {
+			format: YAML,
+			input: `
+log:
+  type: logrus
+  both: all
+  level: info
+  format: json
+  file:
+    mode: date
+    path: /var/log/app
+    size: 60
+    max_age: 7
+`,
+			want: &Config{
+				Type: "logrus", Both: "all", Level: "info", Format: "json",
+				File: FileConf{Mode: "date", Path: "/var/log/app", Size: 60, MaxAge: 7},
+			},
+		},
+		"toml": {
+			format: TOML,
+			input: `
+[log]
+type = "std"
+level = "debug"
+
+[log.file]
+size = 10
+`,
+			want: &Config{
+				Type: "std", Both: "console", Level: "debug", Format: "text",
+				File: FileConf{Mode: "size", Path: "./logs", Size: 10, MaxAge: 90},
+			},
+		},
+		"json": {
+			format: JSON,
+			input:  `{"log": {"both": "file", "file": {"path": "/tmp/logs"}}}`,
+			want: &Config{
+				Type: "zap", Both: "file", Level: "warn", Format: "text",
+				File: FileConf{Mode: "size", Path: "/tmp/logs", Size: 30, MaxAge: 90},
+			},
+		},
+		"empty": {
+			format: YAML,
+			input:  "",
+			want:   Default(),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(test.input), test.format)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := map[string]struct {
+		format string
+		input  string
+		line   int
+	}{
+		"yaml": {
+			format: YAML,
+			input:  "log:\n  level: info\n  file: [\n",
+			line:   3,
+		},
+		"yaml type": {
+			format: YAML,
+			input:  "log:\n  level: info\n  file:\n    size: big\n",
+			line:   4,
+		},
+		"yaml level": {
+			format: YAML,
+			input:  "log:\n  type: zap\n  level: loud\n",
+			line:   3,
+		},
+		"toml": {
+			format: TOML,
+			input:  "[log]\nlevel = \"info\"\nboth = @\n",
+			line:   3,
+		},
+		"json": {
+			format: JSON,
+			input:  "{\n\"log\": {\n\"level\": \"info\",\n}}",
+			line:   4,
+		},
+		"json type": {
+			format: JSON,
+			input:  "{\n\"log\": {\n\"file\": {\"size\": \"big\"}}}",
+			line:   3,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(test.input), test.format)
+			var pe *ParseError
+			if assert.True(t, errors.As(err, &pe), "%v", err) {
+				assert.Equal(t, test.line, pe.Line, "%v", err)
+			}
+		})
+	}
+
+	_, err := Parse(strings.NewReader(""), "ini")
+	assert.Error(t, err)
+
+	for format, input := range map[string]string{
+		TOML: "[log]\nlevel = \"loud\"\n",
+		JSON: `{"log": {"level": "loud"}}`,
+	} {
+		_, err = Parse(strings.NewReader(input), format)
+		assert.ErrorContains(t, err, `unrecognized level "loud"`, format)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	conf, err := Load("../config.yaml.example")
+	assert.NoError(t, err)
+	assert.Equal(t, "zap", conf.Type)
+	assert.Equal(t, "console", conf.Both)
+	assert.Equal(t, Level("info"), conf.Level)
+	assert.Equal(t, Default().File, conf.File)
+
+	path := filepath.Join(t.TempDir(), "log.yml")
+	assert.NoError(t, os.WriteFile(path, []byte("log:\n  level: [\n"), 0o600))
+	_, err = Load(path)
+	assert.Contains(t, err.Error(), path+":2:")
+
+	_, err = Load("log.ini")
+	assert.Error(t, err)
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/ysk229/go-logs"
+)
+
+// Allowed values of the enumerated Config fields, the empty string selects the default.
+var (
+	Types      = []string{"zap", "logrus", "std"}
+	Boths      = []string{"all", "file", "console"}
+	Levels     = []string{"trace", "debug", "info", "warn", "error", "panic", "fatal"}
+	Formats    = []string{"json", "text"}
+	Modes      = []string{"size", "date"}
+	Sinks      = []string{"console", "stdout", "stderr", "file"}
+	Redactions = []string{"mask", "hash", "drop"}
+	Duplicates = []string{"keep_all", "last_wins", "first_wins", "suffix"}
+)
+
+// FieldError describes one invalid Config field.
+type FieldError struct {
+	Field   string      // field name as written in the config file, e.g. "file.mode"
+	Value   interface{} // offending value
+	Allowed []string    // allowed values, empty when Reason is set
+	Reason  string
+}
+
+func (e *FieldError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("%s: invalid value %v: %s", e.Field, e.Value, e.Reason)
+	}
+	return fmt.Sprintf("%s: invalid value %q, allowed: %s", e.Field, e.Value, strings.Join(e.Allowed, ", "))
+}
+
+// ValidationError lists every invalid field of a Config.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Error())
+	}
+	return "config: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks every field of c and returns a *ValidationError listing all
+// invalid ones, or nil.
+func (c *Config) Validate() error {
+	if c == nil {
+		return errors.New("config: nil config")
+	}
+	v := &ValidationError{}
+	v.oneOf("type", c.Type, Types, false)
+	v.oneOf("both", c.Both, Boths, false)
+	v.oneOf("level", string(c.Level), Levels, true)
+	v.nameLevels(c)
+	v.oneOf("format", c.Format, Formats, false)
+	v.file("file", c.File)
+	v.oneOf("pii.redaction", c.PII.Redaction, Redactions, false)
+	detectors := log.DetectorNames()
+	for i, name := range c.PII.Detectors {
+		v.oneOf(fmt.Sprintf("pii.detectors[%d]", i), name, detectors, false)
+	}
+	v.sample("sample", c.Sample)
+	v.oneOf("stack.level", c.Stack.Level, append(Levels[:len(Levels):len(Levels)], StackNone), true)
+	v.nonNegative("stack.depth", c.Stack.Depth)
+	if tz := c.Timestamp.TimeZone; tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			v.Errors = append(v.Errors, &FieldError{Field: "timestamp.time_zone", Value: tz, Reason: "unknown time zone"})
+		}
+	}
+	v.oneOf("duplicate_keys", c.DuplicateKeys, Duplicates, false)
+	for i, s := range c.Sinks {
+		field := fmt.Sprintf("sinks[%d]", i)
+		if s.Type == "" {
+			v.Errors = append(v.Errors, &FieldError{Field: field + ".type", Value: s.Type, Allowed: Sinks})
+		}
+		v.oneOf(field+".type", s.Type, Sinks, false)
+		v.oneOf(field+".format", s.Format, Formats, false)
+		v.oneOf(field+".level", s.Level, Levels, true)
+		v.oneOf(field+".max_level", s.MaxLevel, Levels, true)
+		v.file(field+".file", s.File)
+	}
+	if len(v.Errors) > 0 {
+		return v
+	}
+	return nil
+}
+
+func (v *ValidationError) oneOf(field, value string, allowed []string, fold bool) {
+	if value == "" {
+		return
+	}
+	for _, a := range allowed {
+		if value == a || (fold && strings.EqualFold(value, a)) {
+			return
+		}
+	}
+	v.Errors = append(v.Errors, &FieldError{Field: field, Value: value, Allowed: allowed})
+}
+
+func (v *ValidationError) nameLevels(c *Config) {
+	levels, err := c.NameLevels()
+	if err != nil {
+		v.Errors = append(v.Errors, &FieldError{Field: "levels", Value: c.Levels, Reason: "want name=level,..."})
+		return
+	}
+	names := make([]string, 0, len(levels))
+	for name := range levels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field := "levels." + name
+		if name == "" {
+			field = "levels.*"
+		}
+		v.oneOf(field, levels[name], Levels, true)
+	}
+}
+
+func (v *ValidationError) file(field string, f FileConf) {
+	v.oneOf(field+".mode", f.Mode, Modes, false)
+	v.nonNegative(field+".size", f.Size)
+	v.nonNegative(field+".max_age", f.MaxAge)
+}
+
+func (v *ValidationError) sample(field string, s SampleConf) {
+	if s.Tick != "" {
+		if d, err := time.ParseDuration(s.Tick); err != nil || d <= 0 {
+			v.Errors = append(v.Errors, &FieldError{Field: field + ".tick", Value: s.Tick, Reason: "want a positive duration, e.g. 1s"})
+		}
+	}
+	v.nonNegative(field+".first", s.First)
+	v.nonNegative(field+".thereafter", s.Thereafter)
+	levels := make([]string, 0, len(s.Levels))
+	for level := range s.Levels {
+		levels = append(levels, level)
+	}
+	sort.Strings(levels)
+	for _, level := range levels {
+		v.oneOf(field+".levels", level, Levels, true)
+		v.nonNegative(field+".levels."+level+".first", s.Levels[level].First)
+		v.nonNegative(field+".levels."+level+".thereafter", s.Levels[level].Thereafter)
+	}
+}
+
+func (v *ValidationError) nonNegative(field string, value int) {
+	if value < 0 {
+		v.Errors = append(v.Errors, &FieldError{Field: field, Value: value, Reason: "must not be negative"})
+	}
+}
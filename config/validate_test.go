@@ -0,0 +1,90 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Default().Validate())
+	assert.NoError(t, (&Config{}).Validate())
+	assert.NoError(t, (&Config{Level: "INFO"}).Validate())
+	assert.Error(t, (*Config)(nil).Validate())
+	assert.NoError(t, (&Config{Levels: "db=debug, http=WARN,*=info"}).Validate())
+	assert.EqualError(t, (&Config{Levels: "db=loud,*=quiet"}).Validate(),
+		`config: levels.*: invalid value "quiet", allowed: trace, debug, info, warn, error, panic, fatal; `+
+			`levels.db: invalid value "loud", allowed: trace, debug, info, warn, error, panic, fatal`)
+	assert.Error(t, (&Config{Levels: "db"}).Validate())
+
+	conf := &Config{
+		Type:   "zapp",
+		Both:   "both",
+		Level:  "verbose",
+		Format: "xml",
+		File:   FileConf{Mode: "daily", Size: -1, MaxAge: -2},
+	}
+	err := conf.Validate()
+	var ve *ValidationError
+	if !assert.True(t, errors.As(err, &ve)) {
+		return
+	}
+	fields := make([]string, 0, len(ve.Errors))
+	for _, fe := range ve.Errors {
+		fields = append(fields, fe.Field)
+	}
+	assert.Equal(t, []string{"type", "both", "level", "format", "file.mode", "file.size", "file.max_age"}, fields)
+	assert.Contains(t, err.Error(), `type: invalid value "zapp", allowed: zap, logrus, std`)
+	assert.Contains(t, err.Error(), `file.size: invalid value -1: must not be negative`)
+
+	conf = &Config{Sinks: []Sink{
+		{Type: "console", Level: "debug"},
+		{Type: "syslog", Format: "xml", MaxLevel: "loud", File: FileConf{Mode: "hourly"}},
+		{},
+	}}
+	err = conf.Validate()
+	assert.True(t, errors.As(err, &ve))
+	fields = fields[:0]
+	for _, fe := range ve.Errors {
+		fields = append(fields, fe.Field)
+	}
+	assert.Equal(t, []string{
+		"sinks[1].type", "sinks[1].format", "sinks[1].max_level", "sinks[1].file.mode", "sinks[2].type",
+	}, fields)
+}
+
+func TestValidatePII(t *testing.T) {
+	assert.NoError(t, (&Config{PII: PIIConf{Detectors: []string{"email", "credit_card"}, Redaction: "hash"}}).Validate())
+	assert.EqualError(t, (&Config{PII: PIIConf{Detectors: []string{"email", "ssn"}, Redaction: "blur"}}).Validate(),
+		`config: pii.redaction: invalid value "blur", allowed: mask, hash, drop; `+
+			`pii.detectors[1]: invalid value "ssn", allowed: credit_card, email, ipv4, ipv6, jwt, phone`)
+}
+
+func TestValidateSample(t *testing.T) {
+	assert.NoError(t, (&Config{Sample: SampleConf{Tick: "500ms", First: 10, Levels: map[string]Rate{"debug": {First: 1}}}}).Validate())
+	assert.EqualError(t, (&Config{Sample: SampleConf{Tick: "often", First: -1, Levels: map[string]Rate{"loud": {}}}}).Validate(),
+		`config: sample.tick: invalid value often: want a positive duration, e.g. 1s; `+
+			`sample.first: invalid value -1: must not be negative; `+
+			`sample.levels: invalid value "loud", allowed: trace, debug, info, warn, error, panic, fatal`)
+}
+
+func TestValidateDuplicateKeys(t *testing.T) {
+	assert.NoError(t, (&Config{DuplicateKeys: "suffix"}).Validate())
+	assert.EqualError(t, (&Config{DuplicateKeys: "merge"}).Validate(),
+		`config: duplicate_keys: invalid value "merge", allowed: keep_all, last_wins, first_wins, suffix`)
+}
+
+func TestValidateStack(t *testing.T) {
+	assert.NoError(t, (&Config{Stack: StackConf{Level: "none", Depth: 10}}).Validate())
+	assert.NoError(t, (&Config{Stack: StackConf{Level: "WARN"}}).Validate())
+	assert.EqualError(t, (&Config{Stack: StackConf{Level: "always", Depth: -1}}).Validate(),
+		`config: stack.level: invalid value "always", allowed: trace, debug, info, warn, error, panic, fatal, none; `+
+			`stack.depth: invalid value -1: must not be negative`)
+}
+
+func TestValidateTimestamp(t *testing.T) {
+	assert.NoError(t, (&Config{Timestamp: TimestampConf{Format: "unix_ms", TimeZone: "Europe/Paris"}}).Validate())
+	assert.EqualError(t, (&Config{Timestamp: TimestampConf{TimeZone: "Mars/Olympus"}}).Validate(),
+		`config: timestamp.time_zone: invalid value Mars/Olympus: unknown time zone`)
+}
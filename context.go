@@ -0,0 +1,73 @@
+package log
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+type levelKey struct{}
+
+// ContextWithLevel returns a copy of ctx that carries level. Loggers bound
+// to it with WithContext log every entry at level and above, whatever the
+// configured levels are, e.g. to trace one request at debug while the rest
+// of the service stays at warn.
+func ContextWithLevel(ctx context.Context, level Level) context.Context {
+	return context.WithValue(ctx, levelKey{}, level)
+}
+
+// LevelFromContext returns the level set by ContextWithLevel.
+func LevelFromContext(ctx context.Context) (Level, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	level, ok := ctx.Value(levelKey{}).(Level)
+	return level, ok
+}
+
+// ContextExtractor returns fields read from ctx, e.g. "request_id", id.
+type ContextExtractor func(ctx context.Context) []interface{}
+
+// extractor is a registered ContextExtractor, its address identifies the
+// registration.
+type extractor struct {
+	f ContextExtractor
+}
+
+var extractors struct {
+	mu   sync.Mutex
+	list atomic.Value // []*extractor
+}
+
+// RegisterContextExtractor adds f to the extractors whose fields are appended
+// to every entry of the loggers bound to a context with WithContext. The
+// returned func removes it again, e.g. in t.Cleanup.
+func RegisterContextExtractor(f ContextExtractor) (unregister func()) {
+	e := &extractor{f: f}
+	extractors.mu.Lock()
+	defer extractors.mu.Unlock()
+	list, _ := extractors.list.Load().([]*extractor)
+	extractors.list.Store(append(list[:len(list):len(list)], e))
+	return func() {
+		extractors.mu.Lock()
+		defer extractors.mu.Unlock()
+		list, _ := extractors.list.Load().([]*extractor)
+		kept := make([]*extractor, 0, len(list))
+		for _, x := range list {
+			if x != e {
+				kept = append(kept, x)
+			}
+		}
+		extractors.list.Store(kept)
+	}
+}
+
+// contextFields returns the fields of every registered extractor for ctx.
+func contextFields(ctx context.Context) []interface{} {
+	list, _ := extractors.list.Load().([]*extractor)
+	var kvs []interface{}
+	for _, e := range list {
+		kvs = append(kvs, e.f(ctx)...)
+	}
+	return kvs
+}
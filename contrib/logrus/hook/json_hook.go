@@ -1,18 +1,22 @@
 package hook
 
 import (
+	"encoding/json"
 	"runtime"
 
 	"github.com/sirupsen/logrus"
+
+	log "github.com/ysk229/go-logs"
 )
 
 type JSONFormatter struct {
 	*logrus.JSONFormatter
+	time *log.TimeFormat // see SetTimeFormat
 }
 
 // const TimestampFormat = "2006-01-02T15:04:05.999Z07:00"
 
-const TimestampFormat = "2006-01-02.15:04:05.000000"
+const TimestampFormat = log.DefaultTimeLayout
 
 func NewJSONFormatter() logrus.Formatter {
 	return &JSONFormatter{
@@ -30,6 +34,34 @@ func NewJSONFormatter() logrus.Formatter {
 	}
 }
 
+// SetTimeFormat encodes the timestamps with tf instead of TimestampFormat,
+// as numbers for the unix presets.
+func (f *JSONFormatter) SetTimeFormat(tf log.TimeFormat) {
+	f.time = &tf
+	f.DisableTimestamp = true
+}
+
 func (f *JSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
-	return f.JSONFormatter.Format(entry)
+	b, err := f.JSONFormatter.Format(entry)
+	if err != nil || f.time == nil || len(b) < 2 || b[0] != '{' {
+		return b, err
+	}
+	// logrus only formats timestamps as strings, the field is put first instead
+	ts, err := json.Marshal(f.time.Value(entry.Time))
+	if err != nil {
+		return nil, err
+	}
+	key := f.FieldMap[logrus.FieldKeyTime]
+	if key == "" {
+		key = logrus.FieldKeyTime
+	}
+	out := make([]byte, 0, len(b)+len(key)+len(ts)+5)
+	out = append(out, `{"`...)
+	out = append(out, key...)
+	out = append(out, `":`...)
+	out = append(out, ts...)
+	if b[1] != '}' {
+		out = append(out, ',')
+	}
+	return append(out, b[1:]...), nil
 }
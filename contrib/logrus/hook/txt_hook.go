@@ -15,6 +15,8 @@ import (
 
 	"github.com/mgutz/ansi"
 	"github.com/sirupsen/logrus"
+
+	log "github.com/ysk229/go-logs"
 )
 
 const defaultTimestampFormat = time.RFC3339
@@ -115,6 +117,9 @@ type TextFormatter struct {
 	// Color scheme to use.
 	colorScheme *compiledColorScheme
 
+	// Time format replacing TimestampFormat, see SetTimeFormat.
+	time *log.TimeFormat
+
 	// Whether the logger's out is to a terminal.
 	isTerminal bool
 
@@ -126,7 +131,7 @@ func NewTextFormatter() logrus.Formatter {
 		ForceColors:     true,
 		FullTimestamp:   true,
 		ForceFormatting: true,
-		TimestampFormat: "2006-01-02.15:04:05.000000",
+		TimestampFormat: log.DefaultTimeLayout,
 	}
 	t.SetColorScheme(&ColorScheme{
 		WarnLevelStyle:  "yellow",
@@ -141,6 +146,14 @@ func NewTextFormatter() logrus.Formatter {
 	return t
 }
 
+// NewTextNoColorFormatter returns the text formatter without colors.
+func NewTextNoColorFormatter() logrus.Formatter {
+	t := NewTextFormatter().(*TextFormatter)
+	t.ForceColors = false
+	t.DisableColors = true
+	return t
+}
+
 func getCompiledColor(main string, fallback string) func(string) string {
 	var style string
 	if main != "" {
@@ -186,10 +199,27 @@ func (f *TextFormatter) SetColorScheme(colorScheme *ColorScheme) {
 	f.colorScheme = compileColorScheme(colorScheme)
 }
 
+// SetTimeFormat formats the timestamps with tf instead of TimestampFormat.
+func (f *TextFormatter) SetTimeFormat(tf log.TimeFormat) {
+	f.time = &tf
+}
+
+func (f *TextFormatter) timestamp(t time.Time, layout string) string {
+	if f.time != nil {
+		return f.time.Format(t)
+	}
+	return t.Format(layout)
+}
+
 func (f *TextFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	var b *bytes.Buffer
 	keys := make([]string, 0, len(entry.Data))
-	for k := range entry.Data {
+	var stackKeys []string // printed as blocks
+	for k, v := range entry.Data {
+		if _, ok := v.(log.Stack); ok {
+			stackKeys = append(stackKeys, k)
+			continue
+		}
 		keys = append(keys, k)
 	}
 	lastKeyIdx := len(keys) - 1
@@ -197,6 +227,7 @@ func (f *TextFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	if !f.DisableSorting {
 		sort.Strings(keys)
 	}
+	sort.Strings(stackKeys)
 	if entry.Buffer != nil {
 		b = entry.Buffer
 	} else {
@@ -228,7 +259,7 @@ func (f *TextFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 		f.printColored(b, entry, keys, timestampFormat, colorScheme)
 	} else {
 		if !f.DisableTimestamp {
-			f.appendKeyValue(b, "time", entry.Time.Format(timestampFormat), true)
+			f.appendKeyValue(b, "time", f.timestamp(entry.Time, timestampFormat), true)
 		}
 		f.appendKeyValue(b, "level", entry.Level.String(), true)
 		if entry.Message != "" {
@@ -238,6 +269,14 @@ func (f *TextFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 			f.appendKeyValue(b, key, entry.Data[key], lastKeyIdx != i)
 		}
 	}
+	for _, key := range keys {
+		if d, ok := entry.Data[key].(log.ErrorDetail); ok {
+			b.WriteString(d.Text(key))
+		}
+	}
+	for _, key := range stackKeys {
+		b.WriteString(entry.Data[key].(log.Stack).Text(key))
+	}
 
 	b.WriteByte('\n')
 	return b.Bytes(), nil
@@ -301,7 +340,7 @@ func (f *TextFormatter) printColored(b *bytes.Buffer, entry *logrus.Entry, keys
 		if !f.FullTimestamp {
 			timestamp = fmt.Sprintf("[%04d]", miniTS())
 		} else {
-			timestamp = fmt.Sprintf("[%s]", entry.Time.Format(timestampFormat))
+			timestamp = fmt.Sprintf("[%s]", f.timestamp(entry.Time, timestampFormat))
 		}
 		fmt.Fprintf(b, "%s %s%s "+messageFormat, colorScheme.TimestampColor(timestamp), level, prefix, message)
 	}
@@ -357,7 +396,7 @@ func (f *TextFormatter) appendValue(b *bytes.Buffer, value interface{}) {
 			fmt.Fprintf(b, "%s%v%s", f.QuoteCharacter, value, f.QuoteCharacter)
 		}
 	case error:
-		errmsg := value.Error()
+		errmsg := fmt.Sprint(value) // "<nil>" for a nil pointer, as Error() would panic
 		if !f.needsQuoting(errmsg) {
 			b.WriteString(errmsg)
 		} else {
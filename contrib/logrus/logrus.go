@@ -8,13 +8,14 @@ import (
 
 	log "github.com/ysk229/go-logs"
 	"github.com/ysk229/go-logs/config"
-	"github.com/ysk229/go-logs/file"
 )
 
 var _ log.Logger = (*Logger)(nil)
 
 type Logger struct {
 	logrus *logrus.Logger
+	w      *Write
+	clock  log.TimeFormat // tells the time of the entries
 }
 
 func (l *Logger) SetLevel(level string) {
@@ -26,53 +27,84 @@ func (l *Logger) SetLevel(level string) {
 	l.logrus.SetLevel(parseLevel)
 }
 
-func (l *Logger) Log(level log.Level, keyvals ...interface{}) (err error) {
-	var (
-		logrusLevel logrus.Level
-		fields      logrus.Fields = make(map[string]interface{})
-		msg         string
-	)
+// Enabled reports whether logrus logs entries at level.
+func (l *Logger) Enabled(level log.Level) bool {
+	return l.logrus.IsLevelEnabled(toLogrusLevel(level))
+}
 
+func toLogrusLevel(level log.Level) logrus.Level {
 	switch level {
+	case log.LevelTrace:
+		return logrus.TraceLevel
 	case log.LevelDebug:
-		logrusLevel = logrus.DebugLevel
+		return logrus.DebugLevel
 	case log.LevelInfo:
-		logrusLevel = logrus.InfoLevel
+		return logrus.InfoLevel
 	case log.LevelWarn:
-		logrusLevel = logrus.WarnLevel
+		return logrus.WarnLevel
 	case log.LevelError:
-		logrusLevel = logrus.ErrorLevel
-	default:
-		logrusLevel = logrus.DebugLevel
+		return logrus.ErrorLevel
+	case log.LevelPanic:
+		return logrus.PanicLevel
+	case log.LevelFatal:
+		return logrus.FatalLevel
 	}
+	return logrus.DebugLevel
+}
 
-	if logrusLevel > l.logrus.Level {
+func (l *Logger) Log(level log.Level, keyvals ...interface{}) (err error) {
+	if !l.logrus.IsLevelEnabled(toLogrusLevel(level)) {
 		return nil
 	}
+	return l.log(level, false, keyvals)
+}
+
+// ForceLog is Log whatever the level of logrus: the entry goes straight to
+// the sinks, the hooks.
+func (l *Logger) ForceLog(level log.Level, keyvals ...interface{}) error {
+	return l.log(level, true, keyvals)
+}
+
+func (l *Logger) log(level log.Level, force bool, keyvals []interface{}) error {
+	var (
+		logrusLevel               = toLogrusLevel(level)
+		fields      logrus.Fields = make(map[string]interface{})
+		msg         string
+	)
 
 	if len(keyvals) == 0 {
 		return nil
 	}
-	if len(keyvals)%2 != 0 {
-		keyvals = append(keyvals, "")
-	}
+	keyvals = log.NormalizeKeyvals(keyvals)
 	for i := 0; i < len(keyvals); i += 2 {
-		key, ok := keyvals[i].(string)
-		if !ok {
-			continue
-		}
+		key := keyvals[i].(string)
 		if key == logrus.FieldKeyMsg {
 			msg, _ = keyvals[i+1].(string)
 			continue
 		}
+		if f, ok := keyvals[i+1].(log.Field); ok {
+			fields[key] = f.Value()
+			continue
+		}
 		fields[key] = keyvals[i+1]
 	}
 
+	if logrusLevel == logrus.PanicLevel {
+		// logrus panics once the hooks wrote the entry, that is left to the caller
+		defer func() {
+			_ = recover()
+		}()
+	}
+	entry := l.logrus.WithTime(l.clock.Now())
 	if len(fields) > 0 {
-		l.logrus.WithFields(fields).Log(logrusLevel, msg)
-	} else {
-		l.logrus.Log(logrusLevel, msg)
+		entry = entry.WithFields(fields)
+	}
+	if force && !l.logrus.IsLevelEnabled(logrusLevel) {
+		// logrus drops the entry below its level, the hooks are the only output
+		entry.Level, entry.Message = logrusLevel, msg
+		return l.logrus.Hooks.Fire(logrusLevel, entry)
 	}
+	entry.Log(logrusLevel, msg)
 
 	return nil
 }
@@ -80,7 +112,7 @@ func (l *Logger) Log(level log.Level, keyvals ...interface{}) (err error) {
 func New(conf *config.Config) log.Logger {
 	logger := logrus.New()
 	// 日志级别
-	parseLevel, e := logrus.ParseLevel(conf.Level)
+	parseLevel, e := logrus.ParseLevel(string(conf.Level))
 	if e != nil {
 		parseLevel = logrus.WarnLevel
 	}
@@ -88,39 +120,29 @@ func New(conf *config.Config) log.Logger {
 	// log
 	lg.SetFlags(lg.Lshortfile)
 	lg.SetOutput(logger.Writer())
-	getLog(conf, logger)
 	return &Logger{
 		logrus: logger,
+		w:      getLog(conf, logger),
+		clock:  conf.TimeFormat(),
 	}
 }
 
-func getLog(conf *config.Config, logger *logrus.Logger) {
-	var fileOpts []file.LogOption
-	if len(conf.File.Path) > 0 {
-		fileOpts = append(fileOpts, file.Path(conf.File.Path))
-	}
-	if len(conf.File.Mode) > 0 {
-		fileOpts = append(fileOpts, file.Mode(conf.File.Mode))
-	}
-	if conf.File.MaxAge > 0 {
-		fileOpts = append(fileOpts, file.MaxAge(conf.File.MaxAge))
-	}
-	if conf.File.Size > 0 {
-		fileOpts = append(fileOpts, file.Size(conf.File.Size))
+// Close closes the log files.
+func (l *Logger) Close() error {
+	if l.w == nil {
+		return nil
 	}
+	return l.w.Close()
+}
 
+func getLog(conf *config.Config, logger *logrus.Logger) *Write {
 	w := NewWrite(logger)
-	switch conf.Both {
-	case "all":
-		// log
-		w.setConsoleFormatter(conf.Format)
-		w.writeFile(conf.Format, fileOpts...)
-	case "file":
-		w.writeFile(conf.Format, fileOpts...)
-		w.logger.SetOutput(io.Discard)
-	default:
-		// log
-		lg.SetOutput(logger.Writer())
-		w.setConsoleFormatter(conf.Format)
+	w.time = conf.TimeFormat()
+	// every sink is a hook, the logger's own output is unused
+	logger.SetOutput(io.Discard)
+	logger.SetFormatter(nopFormatter{})
+	for _, sink := range conf.EffectiveSinks() {
+		w.writeSink(sink)
 	}
+	return w
 }
@@ -2,6 +2,10 @@ package logrus
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -41,6 +45,13 @@ func TestLoggerLog(t *testing.T) {
 			kvs:       []interface{}{"msg", "1"},
 			want:      `{"level":"info","msg":"1"`,
 		},
+		"typed fields": {
+			level:     logrus.InfoLevel,
+			formatter: &logrus.JSONFormatter{},
+			logLevel:  log.LevelInfo,
+			kvs:       []interface{}{"n", log.Int64("n", 42), "msg", "1"},
+			want:      `{"level":"info","msg":"1","n":42`,
+		},
 	}
 
 	for name, test := range tests {
@@ -95,3 +106,57 @@ func TestLogger_Log(t *testing.T) {
 		)
 	}
 }
+
+func TestSinks(t *testing.T) {
+	dir := t.TempDir()
+	l := New(&config.Config{
+		Level: "debug",
+		File:  config.FileConf{Path: dir},
+		Sinks: []config.Sink{
+			{Name: "app", Type: "file", Format: "json", Level: "info"},
+			{Name: "errors", Type: "file", Format: "json", Level: "error"},
+			{Name: "debug", Type: "file", Format: "text", MaxLevel: "debug"},
+		},
+	})
+	_ = l.Log(log.LevelDebug, "msg", "debug entry")
+	_ = l.Log(log.LevelInfo, "msg", "info entry")
+	_ = l.Log(log.LevelError, "msg", "error entry")
+	assert.NoError(t, l.(*Logger).Close())
+
+	read := func(name string) string {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		return string(b)
+	}
+	app := read("app.log")
+	assert.NotContains(t, app, "debug entry")
+	assert.Contains(t, app, `"msg":"info entry"`)
+	assert.Contains(t, app, `"msg":"error entry"`)
+	errs := read("errors.log")
+	assert.NotContains(t, errs, "info entry")
+	assert.Contains(t, errs, `"msg":"error entry"`)
+	debug := read("debug.log")
+	assert.Contains(t, debug, "debug entry")
+	assert.NotContains(t, debug, "info entry")
+}
+
+func TestErrors(t *testing.T) {
+	dir := t.TempDir()
+	l := New(&config.Config{
+		Level: "info",
+		File:  config.FileConf{Path: dir},
+		Sinks: []config.Sink{
+			{Name: "json", Type: "file", Format: "json"},
+			{Name: "text", Type: "file", Format: "text"},
+		},
+	})
+	_ = log.With(l).Log(log.LevelWarn, "msg", "save failed", "error", fmt.Errorf("save: %w", errors.New("disk full")))
+	assert.NoError(t, l.(*Logger).Close())
+
+	b, err := os.ReadFile(filepath.Join(dir, "json.log"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"error":{"msg":"save: disk full","types":["*fmt.wrapError","*errors.errorString"]}`)
+	b, err = os.ReadFile(filepath.Join(dir, "text.log"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "=save: disk full\n    error.types: *fmt.wrapError, *errors.errorString\n")
+}
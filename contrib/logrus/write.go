@@ -1,11 +1,16 @@
 package logrus
 
 import (
+	"io"
+	"os"
+	"sync"
+
 	"github.com/mattn/go-colorable"
 	"github.com/rifflock/lfshook"
 	"github.com/sirupsen/logrus"
 
 	log "github.com/ysk229/go-logs"
+	"github.com/ysk229/go-logs/config"
 	"github.com/ysk229/go-logs/contrib/logrus/hook"
 	"github.com/ysk229/go-logs/file"
 )
@@ -13,51 +18,48 @@ import (
 const Mode = "json"
 
 type Write struct {
-	logger *logrus.Logger
+	logger  *logrus.Logger
+	closers []io.Closer
+	time    log.TimeFormat // of the formatters
 }
 
 func NewWrite(logger *logrus.Logger) *Write {
 	return &Write{logger: logger}
 }
 
-func (w *Write) setConsoleFormatter(encodeName string) {
-	if encodeName == Mode {
-		w.logger.SetFormatter(hook.NewJSONFormatter())
-		w.logger.SetOutput(log.NewJSONColorable())
-	} else {
-		w.logger.SetFormatter(hook.NewTextFormatter())
-		w.logger.SetOutput(colorable.NewColorableStdout())
-	}
-}
-
-func (w *Write) writeFile(encodeName string, opts ...file.LogOption) {
-	info := file.NewFileLog("info.log", opts...).SetLogFile()
-	err := file.NewFileLog("error.log", opts...).SetLogFile()
+func (w *Write) writeSink(sink config.Sink) {
+	var out io.Writer
 	fm := hook.NewTextFormatter()
-	if encodeName == Mode {
+	switch sink.Type {
+	case "file":
+		out = w.openFile(sink.File.Name, file.Options(sink.File)...)
+	case "stdout":
+		out = os.Stdout
+		fm = hook.NewTextNoColorFormatter()
+	case "stderr":
+		out = os.Stderr
+		fm = hook.NewTextNoColorFormatter()
+	default:
+		out = colorable.NewColorableStdout()
+		if sink.Format == Mode {
+			out = log.NewJSONColorable()
+		}
+	}
+	if sink.Format == Mode {
 		fm = hook.NewJSONFormatter()
 	}
-	w.logger.AddHook(lfshook.NewHook(
-		lfshook.WriterMap{
-			logrus.DebugLevel: info,
-			logrus.InfoLevel:  info,
-			logrus.WarnLevel:  info,
-			logrus.ErrorLevel: err,
-			logrus.FatalLevel: err,
-			logrus.PanicLevel: err,
-		},
-		fm,
-	))
+	w.logger.AddHook(&sinkHook{levels: sinkLevels(sink.Level, sink.MaxLevel), out: out, fm: w.timed(fm)})
 }
 
 func (w *Write) WriteFileAllLog(encodeName string, opts ...file.LogOption) {
-	log := file.NewFileLog("log.log", opts...).SetLogFile()
+	log := w.openFile("log.log", opts...)
 	fm := hook.NewTextFormatter()
 	if encodeName == Mode {
 		fm = hook.NewJSONFormatter()
 	}
 	w.logger.AddHook(lfshook.NewHook(
 		lfshook.WriterMap{
+			logrus.TraceLevel: log,
 			logrus.DebugLevel: log,
 			logrus.InfoLevel:  log,
 			logrus.WarnLevel:  log,
@@ -65,6 +67,84 @@ func (w *Write) WriteFileAllLog(encodeName string, opts ...file.LogOption) {
 			logrus.FatalLevel: log,
 			logrus.PanicLevel: log,
 		},
-		fm,
+		w.timed(fm),
 	))
 }
+
+// timed sets the time format of fm, a formatter of package hook.
+func (w *Write) timed(fm logrus.Formatter) logrus.Formatter {
+	if f, ok := fm.(interface{ SetTimeFormat(log.TimeFormat) }); ok {
+		f.SetTimeFormat(w.time)
+	}
+	return fm
+}
+
+// openFile opens a log file and remembers it so Close can release it.
+func (w *Write) openFile(fileName string, opts ...file.LogOption) io.Writer {
+	f := file.NewFileLog(fileName, opts...).SetLogFile()
+	if c, ok := f.(io.Closer); ok {
+		w.closers = append(w.closers, c)
+	}
+	return f
+}
+
+// Close closes every file opened by w.
+func (w *Write) Close() error {
+	var err error
+	for _, c := range w.closers {
+		if e := c.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	w.closers = nil
+	return err
+}
+
+// sinkHook writes the entries of its levels to one output.
+type sinkHook struct {
+	mu     sync.Mutex
+	levels []logrus.Level
+	out    io.Writer
+	fm     logrus.Formatter
+}
+
+func (h *sinkHook) Levels() []logrus.Level {
+	return h.levels
+}
+
+func (h *sinkHook) Fire(entry *logrus.Entry) error {
+	b, err := h.fm.Format(entry)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err = h.out.Write(b)
+	return err
+}
+
+// sinkLevels returns the levels within [min, max], an empty bound is open.
+func sinkLevels(min, max string) []logrus.Level {
+	lo, hi := logrus.TraceLevel, logrus.PanicLevel
+	if l, err := logrus.ParseLevel(min); err == nil {
+		lo = l
+	}
+	if l, err := logrus.ParseLevel(max); err == nil {
+		hi = l
+	}
+	levels := make([]logrus.Level, 0, len(logrus.AllLevels))
+	for _, l := range logrus.AllLevels {
+		// logrus orders levels from panic (0) to trace
+		if l <= lo && l >= hi {
+			levels = append(levels, l)
+		}
+	}
+	return levels
+}
+
+// nopFormatter skips formatting for the logger's own output, sinks format on their own.
+type nopFormatter struct{}
+
+func (nopFormatter) Format(*logrus.Entry) ([]byte, error) {
+	return nil, nil
+}
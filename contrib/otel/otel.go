@@ -0,0 +1,161 @@
+// Package otel correlates entries with OpenTelemetry traces: its Valuers and
+// extractor read the span of the context bound with log.WithContext.
+package otel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+
+	log "github.com/ysk229/go-logs"
+)
+
+// Default keys of the fields.
+const (
+	TraceIDKey    = "trace_id"
+	SpanIDKey     = "span_id"
+	TraceFlagsKey = "trace_flags"
+)
+
+// Option is KeyValues and Extractor option.
+type Option func(*options)
+
+type options struct {
+	traceID     string
+	spanID      string
+	traceFlags  string
+	omitInvalid bool
+}
+
+// WithTraceIDKey sets the key of the trace id, empty leaves the field out.
+func WithTraceIDKey(key string) Option {
+	return func(o *options) {
+		o.traceID = key
+	}
+}
+
+// WithSpanIDKey sets the key of the span id, empty leaves the field out.
+func WithSpanIDKey(key string) Option {
+	return func(o *options) {
+		o.spanID = key
+	}
+}
+
+// WithTraceFlagsKey sets the key of the trace flags, empty leaves the field out.
+func WithTraceFlagsKey(key string) Option {
+	return func(o *options) {
+		o.traceFlags = key
+	}
+}
+
+// OmitInvalid leaves the fields out when the context has no valid span: the
+// Valuers of KeyValues return log.Omit, Extractor returns no field.
+func OmitInvalid() Option {
+	return func(o *options) {
+		o.omitInvalid = true
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{traceID: TraceIDKey, spanID: SpanIDKey, traceFlags: TraceFlagsKey}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// TraceID returns a Valuer of the trace id of the span in the context, "" when there is none.
+func TraceID() log.Valuer {
+	return func(ctx context.Context) interface{} {
+		if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+			return sc.TraceID().String()
+		}
+		return ""
+	}
+}
+
+// SpanID returns a Valuer of the span id of the span in the context, "" when there is none.
+func SpanID() log.Valuer {
+	return func(ctx context.Context) interface{} {
+		if sc := trace.SpanContextFromContext(ctx); sc.HasSpanID() {
+			return sc.SpanID().String()
+		}
+		return ""
+	}
+}
+
+// TraceFlags returns a Valuer of the trace flags of the span in the context,
+// e.g. "01" when sampled, "" when there is no valid span.
+func TraceFlags() log.Valuer {
+	return func(ctx context.Context) interface{} {
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			return sc.TraceFlags().String()
+		}
+		return ""
+	}
+}
+
+// KeyValues returns the Valuers with their keys, to be passed to log.With:
+//
+//	logger = log.With(logger, otel.KeyValues()...)
+//	log.WithContext(ctx, logger).Log(log.LevelInfo, "msg", "traced")
+func KeyValues(opts ...Option) []interface{} {
+	o := newOptions(opts)
+	kvs := make([]interface{}, 0, 6)
+	if o.traceID != "" {
+		kvs = append(kvs, o.traceID, o.valuer(TraceID()))
+	}
+	if o.spanID != "" {
+		kvs = append(kvs, o.spanID, o.valuer(SpanID()))
+	}
+	if o.traceFlags != "" {
+		kvs = append(kvs, o.traceFlags, o.valuer(TraceFlags()))
+	}
+	return kvs
+}
+
+// valuer returns v, returning log.Omit when the context has no valid span if
+// o omits invalid spans.
+func (o *options) valuer(v log.Valuer) log.Valuer {
+	if !o.omitInvalid {
+		return v
+	}
+	return func(ctx context.Context) interface{} {
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			return log.Omit
+		}
+		return v(ctx)
+	}
+}
+
+// Extractor returns a context extractor adding the span fields to every
+// entry of the loggers bound to a context:
+//
+//	log.RegisterContextExtractor(otel.Extractor(otel.OmitInvalid()))
+func Extractor(opts ...Option) log.ContextExtractor {
+	o := newOptions(opts)
+	return func(ctx context.Context) []interface{} {
+		sc := trace.SpanContextFromContext(ctx)
+		if !sc.IsValid() && o.omitInvalid {
+			return nil
+		}
+		kvs := make([]interface{}, 0, 6)
+		if o.traceID != "" {
+			kvs = append(kvs, o.traceID, hexOrEmpty(sc.HasTraceID(), sc.TraceID().String))
+		}
+		if o.spanID != "" {
+			kvs = append(kvs, o.spanID, hexOrEmpty(sc.HasSpanID(), sc.SpanID().String))
+		}
+		if o.traceFlags != "" {
+			kvs = append(kvs, o.traceFlags, hexOrEmpty(sc.IsValid(), sc.TraceFlags().String))
+		}
+		return kvs
+	}
+}
+
+func hexOrEmpty(ok bool, f func() string) string {
+	if !ok {
+		return ""
+	}
+	return f()
+}
@@ -0,0 +1,59 @@
+package otel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+
+	log "github.com/ysk229/go-logs"
+)
+
+type recorder struct {
+	entries [][]interface{}
+}
+
+func (r *recorder) Log(level log.Level, keyvals ...interface{}) error {
+	r.entries = append(r.entries, keyvals)
+	return nil
+}
+
+func (r *recorder) SetLevel(string) {}
+
+func (r *recorder) Enabled(log.Level) bool { return true }
+
+func spanContext() context.Context {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled})
+	return trace.ContextWithSpanContext(context.Background(), sc)
+}
+
+func TestKeyValues(t *testing.T) {
+	rec := &recorder{}
+	logger := log.With(rec, KeyValues(WithTraceIDKey("traceId"), WithTraceFlagsKey(""))...)
+
+	_ = log.WithContext(spanContext(), logger).Log(log.LevelInfo, "msg", "traced")
+	_ = logger.Log(log.LevelInfo, "msg", "untraced")
+	assert.Equal(t, []interface{}{"traceId", "4bf92f3577b34da6a3ce929d0e0e4736", "span_id", "00f067aa0ba902b7", "msg", "traced"}, rec.entries[0])
+	assert.Equal(t, []interface{}{"traceId", "", "span_id", "", "msg", "untraced"}, rec.entries[1])
+
+	rec = &recorder{}
+	logger = log.With(rec, KeyValues(WithTraceFlagsKey(""), OmitInvalid())...)
+	_ = log.WithContext(spanContext(), logger).Log(log.LevelInfo, "msg", "traced")
+	_ = logger.Log(log.LevelInfo, "msg", "untraced")
+	assert.Equal(t, []interface{}{"trace_id", "4bf92f3577b34da6a3ce929d0e0e4736", "span_id", "00f067aa0ba902b7", "msg", "traced"}, rec.entries[0])
+	assert.Equal(t, []interface{}{"msg", "untraced"}, rec.entries[1])
+}
+
+func TestExtractor(t *testing.T) {
+	ctx := spanContext()
+	assert.Equal(t, []interface{}{
+		"trace_id", "4bf92f3577b34da6a3ce929d0e0e4736", "span_id", "00f067aa0ba902b7", "trace_flags", "01",
+	}, Extractor()(ctx))
+	assert.Equal(t, []interface{}{"trace_id", "", "span_id", "", "trace_flags", ""}, Extractor()(context.Background()))
+	assert.Nil(t, Extractor(OmitInvalid())(context.Background()))
+	assert.Equal(t, []interface{}{"span", "00f067aa0ba902b7"},
+		Extractor(WithTraceIDKey(""), WithSpanIDKey("span"), WithTraceFlagsKey(""), OmitInvalid())(ctx))
+}
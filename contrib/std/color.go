@@ -36,7 +36,7 @@ func colorPrint(format string, color string, s ...interface{}) string {
 // ColorLog
 func ColorLog(lev string, format string, s ...interface{}) string {
 	switch lev {
-	case "DEBUG":
+	case "TRACE", "DEBUG":
 		return Debug(format, s...)
 	case "INFO":
 		return Info(format, s...)
@@ -45,7 +45,7 @@ func ColorLog(lev string, format string, s ...interface{}) string {
 
 	case "ERROR":
 		return Error(format, s...)
-	case "FATAL":
+	case "PANIC", "FATAL":
 		return Fatal(format, s...)
 	default:
 		return colorPrint(format, ansi.ColorCode("38"), s...)
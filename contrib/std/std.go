@@ -6,6 +6,7 @@ import (
 	"io"
 	l "log"
 	"sync"
+	"sync/atomic"
 
 	"github.com/mattn/go-colorable"
 
@@ -15,14 +16,16 @@ import (
 var _ log.Logger = (*stdLogger)(nil)
 
 type stdLogger struct {
-	log  *l.Logger
-	pool *sync.Pool
+	log   *l.Logger
+	pool  *sync.Pool
+	level int32 // log.Level, every level is printed by default
 }
 
 // NewStdLogger new a logger with writer.
 func NewStdLogger(w io.Writer) log.Logger {
 	return &stdLogger{
-		log: l.New(w, "", 0),
+		log:   l.New(w, "", 0),
+		level: int32(log.LevelTrace),
 		pool: &sync.Pool{
 			New: func() interface{} {
 				return new(bytes.Buffer)
@@ -33,19 +36,25 @@ func NewStdLogger(w io.Writer) log.Logger {
 
 // Log print the kv pairs log.
 func (l *stdLogger) Log(level log.Level, keyvals ...interface{}) error {
-	if len(keyvals) == 0 {
+	if int32(level) < atomic.LoadInt32(&l.level) {
 		return nil
 	}
-	if (len(keyvals) & 1) == 1 {
-		keyvals = append(keyvals, "KEYVALS UNPAIRED")
+	return l.ForceLog(level, keyvals...)
+}
+
+// ForceLog prints the kv pairs log whatever the level.
+func (l *stdLogger) ForceLog(level log.Level, keyvals ...interface{}) error {
+	if len(keyvals) == 0 {
+		return nil
 	}
+	keyvals = log.NormalizeKeyvals(keyvals)
 	l.log.SetOutput(colorable.NewColorableStdout())
 	buf := l.pool.Get().(*bytes.Buffer)
 	var h, b string
 	isMsg := false
 	for i := 0; i < len(keyvals); i += 2 {
 		if keyvals[i] == "ts" {
-			h = ColorLog(level.String(), "[%s] %-8v", keyvals[i+1], level.String())
+			h = ColorLog(level.String(), "[%v] %-8v", keyvals[i+1], level.String())
 			continue
 		}
 
@@ -54,6 +63,9 @@ func (l *stdLogger) Log(level log.Level, keyvals ...interface{}) error {
 			isMsg = true
 			continue
 		}
+		if _, ok := keyvals[i+1].(log.Stack); ok {
+			continue // printed by TextBlocks
+		}
 		b += fmt.Sprintf("%s=%v", ColorLog(level.String(), " %s", keyvals[i]), keyvals[i+1])
 	}
 	buf.WriteString(h)
@@ -61,13 +73,19 @@ func (l *stdLogger) Log(level log.Level, keyvals ...interface{}) error {
 		buf.WriteString(fmt.Sprintf("%-4s", ""))
 	}
 	buf.WriteString(b)
+	buf.WriteString(log.TextBlocks(keyvals...))
 	_ = l.log.Output(4, buf.String()) //nolint:gomnd
 	buf.Reset()
 	l.pool.Put(buf)
 	return nil
 }
 
+func (l *stdLogger) Enabled(level log.Level) bool {
+	return int32(level) >= atomic.LoadInt32(&l.level)
+}
+
 func (l *stdLogger) SetLevel(level string) {
+	atomic.StoreInt32(&l.level, int32(log.ParseLevel(level)))
 }
 
 func (l *stdLogger) Close() error {
@@ -1,6 +1,8 @@
 package std
 
 import (
+	"errors"
+	"fmt"
 	l "log"
 	"testing"
 
@@ -19,4 +21,5 @@ func TestStdLogger(t *testing.T) {
 	_ = logger.Log(log.LevelDebug, "singular", "test")
 	_ = logger.Log(log.LevelWarn, "msg", "test error")
 	_ = logger.Log(log.LevelWarn, "warn singular", "sdfsdfsdf")
+	_ = logger.Log(log.LevelError, "msg", "test wrapped error", "error", fmt.Errorf("save: %w", errors.New("disk full")))
 }
@@ -4,9 +4,95 @@ import (
 	"time"
 
 	"go.uber.org/zap/zapcore"
+
+	log "github.com/ysk229/go-logs"
 )
 
-func NewJSONEncoder() zapcore.Encoder {
+// TraceLevel is the zap level of log.LevelTrace, zap has none below debug.
+const TraceLevel = zapcore.DebugLevel - 1
+
+// LevelString returns the lower case name of level, including TraceLevel.
+func LevelString(level zapcore.Level) string {
+	if level == TraceLevel {
+		return "trace"
+	}
+	return level.String()
+}
+
+// LowercaseLevelEncoder is zapcore.LowercaseLevelEncoder knowing TraceLevel.
+func LowercaseLevelEncoder(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(LevelString(level))
+}
+
+// ErrorDetail is a log.ErrorDetail logged as a zap object: the JSON encoder
+// nests it without reflection, the text encoder prints its message followed
+// by its block.
+type ErrorDetail log.ErrorDetail
+
+// MarshalLogObject implements zapcore.ObjectMarshaler with the keys of the
+// JSON encoding of log.ErrorDetail.
+func (d ErrorDetail) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("msg", d.Message)
+	err := enc.AddArray("types", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		for _, t := range d.Types {
+			arr.AppendString(t)
+		}
+		return nil
+	}))
+	if d.Stack != "" {
+		enc.AddString("stack", d.Stack)
+	}
+	if len(d.Errors) > 0 && err == nil {
+		err = enc.AddArray("errors", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for _, e := range d.Errors {
+				if err := arr.AppendObject(ErrorDetail(e)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+	}
+	return err
+}
+
+// Option is NewJSONEncoder and NewTextEncoder option.
+type Option func(*options)
+
+type options struct {
+	time log.TimeFormat
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithTimeFormat sets how the timestamps and the time fields are encoded,
+// the zero log.TimeFormat by default.
+func WithTimeFormat(f log.TimeFormat) Option {
+	return func(o *options) {
+		o.time = f
+	}
+}
+
+// TimeEncoder encodes times as f.Value returns them, a number for the unix
+// presets and a string otherwise.
+func TimeEncoder(f log.TimeFormat) zapcore.TimeEncoder {
+	return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		switch v := f.Value(t).(type) {
+		case int64:
+			enc.AppendInt64(v)
+		case string:
+			enc.AppendString(v)
+		}
+	}
+}
+
+func NewJSONEncoder(opts ...Option) zapcore.Encoder {
+	o := newOptions(opts)
 	return zapcore.NewJSONEncoder(zapcore.EncoderConfig{
 		TimeKey:       "ts",
 		LevelKey:      "level",
@@ -14,11 +100,9 @@ func NewJSONEncoder() zapcore.Encoder {
 		CallerKey:     "caller",
 		MessageKey:    "msg",
 		StacktraceKey: "stack",
-		EncodeTime: func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
-			enc.AppendString(t.Format("2006-01-02.15:04:05.000000"))
-		},
-		LineEnding:  zapcore.DefaultLineEnding,
-		EncodeLevel: zapcore.LowercaseLevelEncoder,
+		EncodeTime:    TimeEncoder(o.time),
+		LineEnding:    zapcore.DefaultLineEnding,
+		EncodeLevel:   LowercaseLevelEncoder,
 		EncodeDuration: func(d time.Duration, enc zapcore.PrimitiveArrayEncoder) {
 			enc.AppendInt64(int64(d) / 1000000)
 		},
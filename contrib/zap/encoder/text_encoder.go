@@ -14,6 +14,8 @@ import (
 	"github.com/mgutz/ansi"
 	"go.uber.org/zap/buffer"
 	"go.uber.org/zap/zapcore"
+
+	log "github.com/ysk229/go-logs"
 )
 
 var (
@@ -106,6 +108,7 @@ func putTEXTEncoder(enc *textEncoder) {
 	enc.openNamespaces = 0
 	enc.reflectBuf = nil
 	enc.reflectEnc = nil
+	enc.time = log.TimeFormat{}
 	_textPool.Put(enc)
 }
 
@@ -121,14 +124,15 @@ type textEncoder struct {
 	// Color scheme to use.
 	colorScheme *compiledColorScheme
 	isTerminal  bool
+	time        log.TimeFormat
 }
 
-func NewTextNoColorEncoder() zapcore.Encoder {
-	return NewTextEncoder(false)
+func NewTextNoColorEncoder(opts ...Option) zapcore.Encoder {
+	return NewTextEncoder(false, opts...)
 }
 
-func NewTextColorEncoder() zapcore.Encoder {
-	return NewTextEncoder(true)
+func NewTextColorEncoder(opts ...Option) zapcore.Encoder {
+	return NewTextEncoder(true, opts...)
 }
 
 // NewTextEncoder creates a fast, low-allocation TEXT encoder. The encoder
@@ -141,7 +145,8 @@ func NewTextColorEncoder() zapcore.Encoder {
 // libraries will ignore duplicate key-value pairs (typically keeping the last
 // pair) when unmarshaling, but users should attempt to avoid adding duplicate
 // keys.
-func NewTextEncoder(isTerminal bool) zapcore.Encoder {
+func NewTextEncoder(isTerminal bool, opts ...Option) zapcore.Encoder {
+	o := newOptions(opts)
 	cfg := zapcore.EncoderConfig{
 		TimeKey:       "ts",
 		LevelKey:      "level",
@@ -149,8 +154,11 @@ func NewTextEncoder(isTerminal bool) zapcore.Encoder {
 		CallerKey:     "caller",
 		MessageKey:    "msg",
 		StacktraceKey: "stack",
+		EncodeTime:    TimeEncoder(o.time),
 	}
-	return newTextEncoder(cfg, isTerminal)
+	t := newTextEncoder(cfg, isTerminal)
+	t.time = o.time
+	return t
 }
 
 func newTextEncoder(cfg zapcore.EncoderConfig, isTerminal bool) *textEncoder {
@@ -424,7 +432,7 @@ func (enc *textEncoder) levelColor(level zapcore.Level, colorScheme *compiledCol
 	}
 	levelText = "warn"
 	if level != zapcore.WarnLevel {
-		levelText = level.String()
+		levelText = LevelString(level)
 	}
 
 	levelText = strings.ToUpper(levelText)
@@ -444,7 +452,7 @@ func (enc *textEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (
 	level, levelColor := enc.levelColor(ent.Level, colorScheme)
 
 	if final.TimeKey != "" {
-		timestamp := fmt.Sprintf("[%s]", ent.Time.Format("2006-01-02.15:04:05.000000"))
+		timestamp := fmt.Sprintf("[%s]", final.time.Format(ent.Time))
 		final.buf.AppendString(colorScheme.TimestampColor(timestamp))
 	}
 	if final.LevelKey != "" {
@@ -465,9 +473,20 @@ func (enc *textEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (
 	if final.MessageKey != "" {
 		final.buf.AppendString(ent.Message)
 	}
+	var blocks string
 	for _, k := range fields {
-		final.AddString(levelColor(k.Key), k.String)
+		if d, ok := k.Interface.(ErrorDetail); ok && k.Type == zapcore.ObjectMarshalerType {
+			final.AddString(levelColor(k.Key), d.Message)
+			blocks += log.ErrorDetail(d).Text(k.Key)
+			continue
+		}
+		if s, ok := k.Interface.(log.Stack); ok && k.Type == zapcore.ReflectType {
+			blocks += s.Text(k.Key)
+			continue
+		}
+		final.addField(levelColor(k.Key), k)
 	}
+	final.buf.AppendString(blocks)
 	if ent.Stack != "" && final.StacktraceKey != "" {
 		final.addKey(levelColor(final.StacktraceKey))
 		final.buf.AppendString(ent.Stack)
@@ -482,11 +501,53 @@ func (enc *textEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (
 	return ret, nil
 }
 
+// addField writes f as key=value, the value unquoted as the message is.
+func (enc *textEncoder) addField(key string, f zapcore.Field) {
+	switch f.Type {
+	case zapcore.StringType:
+		enc.AddString(key, f.String)
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type:
+		enc.addKey(key)
+		enc.buf.AppendInt(f.Integer)
+	case zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type, zapcore.UintptrType:
+		enc.addKey(key)
+		enc.buf.AppendUint(uint64(f.Integer))
+	case zapcore.BoolType:
+		enc.addKey(key)
+		enc.buf.AppendBool(f.Integer == 1)
+	case zapcore.Float64Type:
+		enc.addKey(key)
+		enc.buf.AppendFloat(math.Float64frombits(uint64(f.Integer)), 64)
+	case zapcore.DurationType:
+		enc.AddString(key, time.Duration(f.Integer).String())
+	case zapcore.TimeType:
+		t := time.Unix(0, f.Integer)
+		if loc, ok := f.Interface.(*time.Location); ok {
+			t = t.In(loc)
+		}
+		enc.AddString(key, enc.time.Format(t))
+	case zapcore.TimeFullType:
+		enc.AddString(key, enc.time.Format(f.Interface.(time.Time)))
+	case zapcore.ErrorType:
+		enc.AddString(key, fmt.Sprint(f.Interface)) // "<nil>" for a nil pointer
+	case zapcore.StringerType:
+		enc.AddString(key, fmt.Sprint(f.Interface))
+	case zapcore.ArrayMarshalerType, zapcore.ObjectMarshalerType:
+		if err := enc.AddReflected(key, f.Interface); err != nil {
+			enc.AddString(key, fmt.Sprint(f.Interface))
+		}
+	default:
+		f.Key = key
+		f.AddTo(enc)
+	}
+}
+
 func (enc *textEncoder) clone() *textEncoder {
 	clone := getTEXTEncoder()
 	clone.EncoderConfig = enc.EncoderConfig
 	clone.spaced = enc.spaced
 	clone.openNamespaces = enc.openNamespaces
+	clone.time = enc.time
 	clone.buf = buffer.NewPool().Get()
 	return clone
 }
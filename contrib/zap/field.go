@@ -0,0 +1,29 @@
+package zap
+
+import (
+	"go.uber.org/zap"
+
+	log "github.com/ysk229/go-logs"
+	"github.com/ysk229/go-logs/contrib/zap/encoder"
+)
+
+// zapField maps f onto the zap.Field of its type, without reflection.
+func zapField(key string, f log.Field) zap.Field {
+	switch f := f.(type) {
+	case *log.StringField:
+		return zap.String(key, f.Val)
+	case *log.Int64Field:
+		return zap.Int64(key, f.Val)
+	case *log.DurationField:
+		return zap.Duration(key, f.Val)
+	case *log.TimeField:
+		return zap.Time(key, f.Val)
+	case *log.ErrorField:
+		// log.With turns errors into an ErrorDetail first, not a Logger used on its own
+		if f.Val != nil {
+			return zap.Object(key, encoder.ErrorDetail(log.NewErrorDetail(f.Val)))
+		}
+		return zap.Skip()
+	}
+	return zap.Any(key, f.Value())
+}
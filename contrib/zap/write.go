@@ -1,7 +1,12 @@
 package zap
 
 import (
+	"io"
+	"os"
+	"strings"
+
 	log "github.com/ysk229/go-logs"
+	"github.com/ysk229/go-logs/config"
 	"github.com/ysk229/go-logs/contrib/zap/encoder"
 	"github.com/ysk229/go-logs/file"
 
@@ -15,17 +20,21 @@ const Mode = "json"
 const bufferSize = 4096
 
 type Write struct {
-	confLevel string
+	level   zap.AtomicLevel
+	closers []io.Closer
+	time    log.TimeFormat // of the encoders
 }
 
 func NewWrite(level string) *Write {
-	return &Write{confLevel: level}
+	return &Write{level: zap.NewAtomicLevelAt(Level(level))}
 }
 
 func Level(confLevel string) zapcore.Level {
 	// 设置级别
 	logLevel := zap.WarnLevel
-	switch confLevel {
+	switch strings.ToLower(confLevel) {
+	case "trace":
+		logLevel = encoder.TraceLevel
 	case "debug":
 		logLevel = zap.DebugLevel
 	case "info":
@@ -43,47 +52,101 @@ func Level(confLevel string) zapcore.Level {
 }
 
 func (w *Write) SetLevel(level string) {
-	w.confLevel = level
+	w.level.SetLevel(Level(level))
 }
 
 func (w *Write) setFileEncodeName(encodeName string) zapcore.Encoder {
 	if encodeName == Mode {
-		return encoder.NewJSONEncoder()
+		return encoder.NewJSONEncoder(encoder.WithTimeFormat(w.time))
 	}
-	return encoder.NewTextNoColorEncoder()
+	return encoder.NewTextNoColorEncoder(encoder.WithTimeFormat(w.time))
 }
 
 func (w *Write) setConsoleEncodeName(encodeName string) zapcore.Encoder {
 	if encodeName == Mode {
-		return encoder.NewJSONEncoder()
+		return encoder.NewJSONEncoder(encoder.WithTimeFormat(w.time))
 	}
-	return encoder.NewTextColorEncoder()
+	return encoder.NewTextColorEncoder(encoder.WithTimeFormat(w.time))
 }
 
 func (w *Write) SetFileInfo() zap.LevelEnablerFunc {
 	return zap.LevelEnablerFunc(func(lev zapcore.Level) bool {
-		return lev < zap.ErrorLevel && lev >= zap.DebugLevel && lev >= Level(w.confLevel)
+		return lev < zap.ErrorLevel && lev >= encoder.TraceLevel && lev >= w.level.Level()
 	})
 }
 
 func (w *Write) SetFileError() zap.LevelEnablerFunc {
 	return zap.LevelEnablerFunc(func(lev zapcore.Level) bool {
-		return lev >= zap.ErrorLevel && lev >= Level(w.confLevel)
+		return lev >= zap.ErrorLevel && lev >= w.level.Level()
 	})
 }
 
 func (w *Write) SetFileAll() zap.LevelEnablerFunc {
 	return zap.LevelEnablerFunc(func(lev zapcore.Level) bool {
-		return lev >= Level(w.confLevel)
+		return lev >= w.level.Level()
+	})
+}
+
+// SetSinkLevel enables the levels of a sink: at least the configured level and
+// within [min, max], an empty bound is open.
+func (w *Write) SetSinkLevel(min, max string) zap.LevelEnablerFunc {
+	return zap.LevelEnablerFunc(func(lev zapcore.Level) bool {
+		return lev >= w.level.Level() &&
+			(min == "" || lev >= Level(min)) &&
+			(max == "" || lev <= Level(max))
+	})
+}
+
+// sinkRange enables the levels of a sink within [min, max], whatever the
+// configured level, which levelCore applies on top.
+func sinkRange(min, max string) zap.LevelEnablerFunc {
+	return zap.LevelEnablerFunc(func(lev zapcore.Level) bool {
+		return (min == "" || lev >= Level(min)) &&
+			(max == "" || lev <= Level(max))
 	})
 }
 
+// levelCore is a core filtered by the configured level, so that the core it
+// wraps can still log the entries forced past that level.
+type levelCore struct {
+	zapcore.Core
+	level zapcore.LevelEnabler
+}
+
+func (c levelCore) Enabled(lev zapcore.Level) bool {
+	return c.level.Enabled(lev) && c.Core.Enabled(lev)
+}
+
+func (c levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return levelCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+func (w *Write) writeSink(sink config.Sink) zapcore.Core {
+	lev := sinkRange(sink.Level, sink.MaxLevel)
+	switch sink.Type {
+	case "file":
+		return w.writeFile(sink.Format, lev, sink.File.Name, file.Options(sink.File)...)
+	case "stdout":
+		return zapcore.NewCore(w.setFileEncodeName(sink.Format), zapcore.Lock(os.Stdout), lev)
+	case "stderr":
+		return zapcore.NewCore(w.setFileEncodeName(sink.Format), zapcore.Lock(os.Stderr), lev)
+	}
+	return w.writeConsole(sink.Format, lev)
+}
+
 func (w *Write) writeFile(encodeName string, lev zapcore.LevelEnabler, fileName string,
 	opts ...file.LogOption,
 ) zapcore.Core {
 	return zapcore.NewCore(
 		w.setFileEncodeName(encodeName),
-		zapcore.AddSync(file.NewFileLog(fileName, opts...).SetLogFile()),
+		zapcore.AddSync(w.openFile(fileName, opts...)),
 		lev,
 	)
 }
@@ -92,16 +155,45 @@ func (w *Write) writeFile(encodeName string, lev zapcore.LevelEnabler, fileName
 func (w *Write) WriteAsyncFile(encodeName string, lev zapcore.LevelEnabler, fileName string,
 	opts ...file.LogOption,
 ) zapcore.Core {
+	ws := &zapcore.BufferedWriteSyncer{
+		WS:   zapcore.AddSync(w.openFile(fileName, opts...)),
+		Size: bufferSize,
+	}
+	w.closers = append(w.closers, closerFunc(ws.Stop))
 	return zapcore.NewCore(
 		w.setFileEncodeName(encodeName),
-		&zapcore.BufferedWriteSyncer{
-			WS:   zapcore.AddSync(file.NewFileLog(fileName, opts...).SetLogFile()),
-			Size: bufferSize,
-		},
+		ws,
 		lev,
 	)
 }
 
+// openFile opens a log file and remembers it so Close can release it.
+func (w *Write) openFile(fileName string, opts ...file.LogOption) io.Writer {
+	f := file.NewFileLog(fileName, opts...).SetLogFile()
+	if c, ok := f.(io.Closer); ok {
+		w.closers = append(w.closers, c)
+	}
+	return f
+}
+
+// Close flushes and closes every file opened by w, last opened first.
+func (w *Write) Close() error {
+	var err error
+	for i := len(w.closers) - 1; i >= 0; i-- {
+		if e := w.closers[i].Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	w.closers = nil
+	return err
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
 func (w *Write) writeConsole(encodeName string, lev zapcore.LevelEnabler) zapcore.Core {
 	io := colorable.NewColorableStdout()
 	if encodeName == Mode {
@@ -1,8 +1,9 @@
 package zap
 
 import (
-	"fmt"
 	log2 "log"
+	"sync"
+	"time"
 
 	log "github.com/ysk229/go-logs"
 
@@ -10,130 +11,216 @@ import (
 	"go.uber.org/zap/zapcore"
 
 	"github.com/ysk229/go-logs/config"
-	"github.com/ysk229/go-logs/file"
+	"github.com/ysk229/go-logs/contrib/zap/encoder"
 )
 
 var _ log.Logger = (*Logger)(nil)
 
 type Logger struct {
-	zap *zap.Logger
-	w   *Write
+	zap         *zap.Logger
+	forced      *zap.Logger // zap without the configured level, see ForceLog
+	w           *Write
+	named       sync.Map // logger name -> *zap.Logger
+	forcedNamed sync.Map
+	stack       log.Logger // the conf.Stack traces over direct, for the entries without one
 }
 
+// direct is the Logger writing to zap, below its stack tracer.
+type direct Logger
+
 func New(conf *config.Config) *Logger {
-	w := NewWrite(conf.Level)
-	l := getLog(conf, w)
+	w := NewWrite(string(conf.Level))
+	forced := getLog(conf, w)
+	l := forced.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return levelCore{Core: c, level: w.level}
+	}))
 	zap.RedirectStdLog(l)
 	zap.ReplaceGlobals(l)
 	log2.SetFlags(log2.Lshortfile)
 
-	return &Logger{zap: l, w: w}
+	logger := &Logger{zap: l, forced: forced, w: w}
+	logger.stack = conf.StackTracer((*direct)(logger))
+	return logger
 }
 
+// Log writes the entry, with the stack trace conf.Stack asks for unless it
+// has one, added by log/ with the same conf.
 func (l *Logger) Log(level log.Level, keyvals ...interface{}) error {
-	if len(keyvals) == 0 || len(keyvals)%2 != 0 {
-		l.zap.Warn(fmt.Sprint("Keyvalues must appear in pairs : ", keyvals))
-		return nil
+	if hasStack(keyvals) {
+		return l.log(l.zap, &l.named, level, keyvals)
 	}
+	return l.stack.Log(level, keyvals...)
+}
+
+// ForceLog is Log whatever the configured level, the level range of each
+// sink still applies.
+func (l *Logger) ForceLog(level log.Level, keyvals ...interface{}) error {
+	if hasStack(keyvals) {
+		return l.log(l.forced, &l.forcedNamed, level, keyvals)
+	}
+	return log.ForceLog(l.stack, level, keyvals...)
+}
+
+func (d *direct) Log(level log.Level, keyvals ...interface{}) error {
+	return (*Logger)(d).log(d.zap, &d.named, level, keyvals)
+}
+
+func (d *direct) ForceLog(level log.Level, keyvals ...interface{}) error {
+	return (*Logger)(d).log(d.forced, &d.forcedNamed, level, keyvals)
+}
+
+func (d *direct) SetLevel(level string) {
+	(*Logger)(d).SetLevel(level)
+}
+
+func (d *direct) Enabled(level log.Level) bool {
+	return (*Logger)(d).Enabled(level)
+}
+
+// hasStack reports whether keyvals hold a log.Stack.
+func hasStack(keyvals []interface{}) bool {
+	for _, v := range keyvals {
+		if _, ok := v.(log.Stack); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// log logs the entry with zl, or its child named by NameKey taken from named.
+func (l *Logger) log(zl *zap.Logger, named *sync.Map, level log.Level, keyvals []interface{}) error {
 	if len(keyvals) == 0 {
 		return nil
 	}
-	if len(keyvals)%2 != 0 {
-		keyvals = append(keyvals, "")
-	}
-	var data []zap.Field
+	keyvals = log.NormalizeKeyvals(keyvals)
+	data := make([]zap.Field, 0, len(keyvals)/2)
 	msg := ""
 	for i := 0; i < len(keyvals); i += 2 {
-		key, ok := keyvals[i].(string)
-		if !ok {
-			continue
-		}
+		key := keyvals[i].(string)
 		if key == "msg" {
 			msg, _ = keyvals[i+1].(string)
 			continue
 		}
-		data = append(data, zap.Any(fmt.Sprint(keyvals[i]), keyvals[i+1]))
+		if name, ok := keyvals[i+1].(string); ok && key == log.NameKey {
+			zl = namedLogger(zl, named, name)
+			continue
+		}
+		if f, ok := keyvals[i+1].(log.Field); ok {
+			data = append(data, zapField(key, f))
+			continue
+		}
+		switch v := keyvals[i+1].(type) {
+		case log.ErrorDetail:
+			// zap.Any would take it for a fmt.Stringer
+			data = append(data, zap.Object(key, encoder.ErrorDetail(v)))
+			continue
+		case log.Stack:
+			// left to the encoders, printed as a block by text
+			data = append(data, zap.Reflect(key, v))
+			continue
+		}
+		data = append(data, zap.Any(key, keyvals[i+1]))
 	}
 	switch level {
+	case log.LevelTrace:
+		if ce := zl.Check(encoder.TraceLevel, msg); ce != nil {
+			ce.Write(data...)
+		}
 	case log.LevelDebug:
-		l.zap.Debug(msg, data...)
+		zl.Debug(msg, data...)
 	case log.LevelInfo:
-		l.zap.Info(msg, data...)
+		zl.Info(msg, data...)
 	case log.LevelWarn:
-		l.zap.Warn(msg, data...)
+		zl.Warn(msg, data...)
 	case log.LevelError:
-		l.zap.Error(msg, data...)
+		zl.Error(msg, data...)
+	case log.LevelPanic:
+		writePanic(zl, msg, data)
 	case log.LevelFatal:
-		l.zap.Fatal(msg, data...)
+		zl.Fatal(msg, data...)
 	}
 	return nil
 }
 
+// writePanic writes a panic entry, zap flushes it to the files, without
+// panicking: that is left to the caller.
+func writePanic(zl *zap.Logger, msg string, data []zap.Field) {
+	defer func() {
+		_ = recover()
+	}()
+	zl.Panic(msg, data...)
+}
+
+// namedLogger returns the child of zl named name, cached in named, so the
+// encoders fill NameKey.
+func namedLogger(zl *zap.Logger, named *sync.Map, name string) *zap.Logger {
+	if child, ok := named.Load(name); ok {
+		return child.(*zap.Logger)
+	}
+	child, _ := named.LoadOrStore(name, zl.Named(name))
+	return child.(*zap.Logger)
+}
+
 func (l *Logger) Sync() error {
 	return l.zap.Sync()
 }
 
+// Enabled reports whether any sink of l logs entries at level.
+func (l *Logger) Enabled(level log.Level) bool {
+	return l.zap.Core().Enabled(toZapLevel(level))
+}
+
+func toZapLevel(level log.Level) zapcore.Level {
+	switch level {
+	case log.LevelTrace:
+		return encoder.TraceLevel
+	case log.LevelDebug:
+		return zapcore.DebugLevel
+	case log.LevelInfo:
+		return zapcore.InfoLevel
+	case log.LevelWarn:
+		return zapcore.WarnLevel
+	case log.LevelError:
+		return zapcore.ErrorLevel
+	case log.LevelPanic:
+		return zapcore.PanicLevel
+	}
+	return zapcore.FatalLevel
+}
+
 func (l *Logger) SetLevel(level string) {
 	l.w.SetLevel(level)
 }
 
+// Close flushes buffered entries and closes the log files.
 func (l *Logger) Close() error {
-	return l.Sync()
+	_ = l.Sync()
+	return l.w.Close()
 }
 
 func getLog(conf *config.Config, w *Write) *zap.Logger {
 	lev := "warn"
-
-	both := ""
-	format := "text"
-	var fileOpts []file.LogOption
+	sinks := (&config.Config{}).EffectiveSinks()
 	if conf != nil {
-		lev = conf.Level
-		if len(conf.File.Path) > 0 {
-			fileOpts = append(fileOpts, file.Path(conf.File.Path))
-		}
-		if len(conf.File.Mode) > 0 {
-			fileOpts = append(fileOpts, file.Mode(conf.File.Mode))
-		}
-		if conf.File.MaxAge > 0 {
-			fileOpts = append(fileOpts, file.MaxAge(conf.File.MaxAge))
-		}
-		if conf.File.Size > 0 {
-			fileOpts = append(fileOpts, file.Size(conf.File.Size))
-		}
-		both = conf.Both
-		format = conf.Format
+		lev = string(conf.Level)
+		sinks = conf.EffectiveSinks()
 	}
 	w.SetLevel(lev)
-	switch both {
-	case "all":
-		return zap.New(
-			zapcore.NewTee(
-				w.writeFile(format, w.SetFileInfo(), "info.log", fileOpts...),
-				w.writeFile(format, w.SetFileError(), "error.log", fileOpts...),
-				w.writeConsole(format, w.SetFileAll()),
-			),
-			zap.AddStacktrace(
-				zap.NewAtomicLevelAt(zapcore.ErrorLevel),
-			),
-		)
-	case "file":
-		return zap.New(
-			zapcore.NewTee(
-				w.writeFile(format, w.SetFileInfo(), "info.log", fileOpts...),
-				w.writeFile(format, w.SetFileError(), "error.log", fileOpts...),
-			),
-			zap.AddStacktrace(
-				zap.NewAtomicLevelAt(zapcore.ErrorLevel),
-			),
-		)
+	w.time = conf.TimeFormat()
+	cores := make([]zapcore.Core, 0, len(sinks))
+	for _, sink := range sinks {
+		cores = append(cores, w.writeSink(sink))
 	}
-	return zap.New(
-		zapcore.NewTee(
-			w.writeConsole(format, w.SetFileAll()),
-		),
-		zap.AddStacktrace(
-			zap.NewAtomicLevelAt(zapcore.ErrorLevel),
-		),
-	)
+	// stack traces are added by log.StackTracer, the same for every backend, see Logger.Log
+	return zap.New(zapcore.NewTee(cores...), zap.WithClock(clock{w.time}))
+}
+
+// clock is the zapcore.Clock of the entries, reading the time of the
+// log.TimeFormat.
+type clock struct {
+	log.TimeFormat
+}
+
+func (c clock) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
 }
@@ -1,7 +1,16 @@
 package zap
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
 
 	log "github.com/ysk229/go-logs"
 	"github.com/ysk229/go-logs/config"
@@ -43,3 +52,178 @@ func Test_zapLogger_Log(t *testing.T) {
 		)
 	}
 }
+
+func TestSinks(t *testing.T) {
+	dir := t.TempDir()
+	l := New(&config.Config{
+		Level: "debug",
+		File:  config.FileConf{Path: dir},
+		Sinks: []config.Sink{
+			{Name: "app", Type: "file", Format: "json", Level: "info"},
+			{Name: "errors", Type: "file", Format: "json", Level: "error"},
+			{Name: "debug", Type: "file", Format: "text", MaxLevel: "debug"},
+		},
+	})
+	_ = l.Log(log.LevelDebug, "msg", "debug entry")
+	_ = l.Log(log.LevelInfo, "msg", "info entry")
+	_ = l.Log(log.LevelError, "msg", "error entry")
+	assert.NoError(t, l.Close())
+
+	read := func(name string) string {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		return string(b)
+	}
+	app := read("app.log")
+	assert.NotContains(t, app, "debug entry")
+	assert.Contains(t, app, `"msg":"info entry"`)
+	assert.Contains(t, app, `"msg":"error entry"`)
+	errs := read("errors.log")
+	assert.NotContains(t, errs, "info entry")
+	assert.Contains(t, errs, `"msg":"error entry"`)
+	debug := read("debug.log")
+	assert.Contains(t, debug, "debug entry")
+	assert.NotContains(t, debug, "info entry")
+}
+
+func TestForceLog(t *testing.T) {
+	dir := t.TempDir()
+	l := New(&config.Config{
+		Level: "warn",
+		Sinks: []config.Sink{
+			{Name: "all", Type: "file", Format: "json", File: config.FileConf{Path: dir}},
+			{Name: "errors", Type: "file", Format: "json", Level: "error", File: config.FileConf{Path: dir}},
+		},
+	})
+	_ = l.Log(log.LevelDebug, "msg", "plain debug")
+	_ = l.ForceLog(log.LevelDebug, log.NameKey, "db", "msg", "forced debug")
+	zap.L().Debug("global debug")
+	assert.False(t, l.Enabled(log.LevelInfo))
+	assert.NoError(t, l.Close())
+
+	all, err := os.ReadFile(filepath.Join(dir, "all.log"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(all), `"logger":"db","msg":"forced debug"`)
+	assert.NotContains(t, string(all), "plain debug")
+	assert.NotContains(t, string(all), "global debug")
+	// the level range of a sink still applies, the file is never written
+	_, err = os.Stat(filepath.Join(dir, "errors.log"))
+	assert.True(t, os.IsNotExist(err), err)
+}
+
+func TestStack(t *testing.T) {
+	dir := t.TempDir()
+	l := New(&config.Config{
+		Level: "info",
+		File:  config.FileConf{Path: dir},
+		Sinks: []config.Sink{{Name: "all", Type: "file", Format: "json"}},
+		Stack: config.StackConf{Level: "warn"},
+	})
+	_ = l.Log(log.LevelInfo, "msg", "no stack")
+	_ = l.Log(log.LevelWarn, "msg", "own stack")
+	_ = l.Log(log.LevelError, "msg", "given stack", log.StackKey, log.Stack("from log/"))
+	assert.NoError(t, l.Close())
+
+	b, err := os.ReadFile(filepath.Join(dir, "all.log"))
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	assert.Len(t, lines, 3)
+	assert.NotContains(t, lines[0], `"stack"`)
+	// without log/, the backend adds the stack trace of conf.Stack itself
+	assert.Contains(t, lines[1], `"stack":"github.com/ysk229/go-logs/contrib/zap.TestStack`)
+	assert.Contains(t, lines[2], `"stack":"from log/"`)
+	assert.Equal(t, 1, strings.Count(lines[2], `"stack"`))
+}
+
+func TestFields(t *testing.T) {
+	dir := t.TempDir()
+	l := New(&config.Config{
+		Level: "info",
+		File:  config.FileConf{Path: dir},
+		Sinks: []config.Sink{
+			{Name: "json", Type: "file", Format: "json"},
+			{Name: "text", Type: "file", Format: "text"},
+		},
+	})
+	ts := time.Date(2022, 9, 1, 10, 0, 0, 0, time.UTC)
+	logger := log.With(l, log.String("svc", "api"))
+	_ = logger.Log(log.LevelInfo, "msg", "typed", log.Int64("n", 42), log.Duration("took", 1500*time.Millisecond),
+		log.Time("at", ts), log.Err(errors.New("boom")), log.Any("tags", []string{"a"}), "user", "bob")
+	assert.NoError(t, l.Close())
+
+	b, err := os.ReadFile(filepath.Join(dir, "json.log"))
+	assert.NoError(t, err)
+	js := string(b)
+	for _, want := range []string{`"svc":"api"`, `"n":42`, `"took":1500`, `"at":`, `"error":{"msg":"boom","types":["*errors.errorString"]}`, `"tags":["a"]`, `"user":"bob"`} {
+		assert.Contains(t, js, want)
+	}
+	b, err = os.ReadFile(filepath.Join(dir, "text.log"))
+	assert.NoError(t, err)
+	text := string(b)
+	for _, want := range []string{"svc=api", "n=42", "took=1.5s", "at=2022-09-01.10:00:00.000000", "error=boom", `tags=["a"]`, "user=bob"} {
+		assert.Contains(t, text, want)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	dir := t.TempDir()
+	l := New(&config.Config{
+		Level: "info",
+		File:  config.FileConf{Path: dir},
+		Sinks: []config.Sink{
+			{Name: "json", Type: "file", Format: "json"},
+			{Name: "text", Type: "file", Format: "text"},
+		},
+	})
+	_ = log.With(l).Log(log.LevelWarn, "msg", "save failed", "error", fmt.Errorf("save: %w", errors.New("disk full")))
+	// without log.With, a log.Err field is encoded the same
+	_ = l.Log(log.LevelWarn, "msg", "load failed", "error", log.Err(errors.New("no disk")))
+	_ = l.Log(log.LevelWarn, "msg", "sync failed", "error", log.NewErrorDetail(multiError{errors.New("a")}))
+	assert.NoError(t, l.Close())
+
+	b, err := os.ReadFile(filepath.Join(dir, "json.log"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"error":{"msg":"save: disk full","types":["*fmt.wrapError","*errors.errorString"]}`)
+	assert.Contains(t, string(b), `"error":{"msg":"no disk","types":["*errors.errorString"]}`)
+	assert.Contains(t, string(b), `"error":{"msg":"1 errors","types":["zap.multiError"],"errors":[{"msg":"a","types":["*errors.errorString"]}]}`)
+	b, err = os.ReadFile(filepath.Join(dir, "text.log"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "error=save: disk full\n    error.types: *fmt.wrapError, *errors.errorString\n")
+}
+
+type multiError []error
+
+func (m multiError) Error() string   { return fmt.Sprintf("%d errors", len(m)) }
+func (m multiError) Errors() []error { return m }
+
+func benchmarkLogger(b *testing.B) log.Logger {
+	l := New(&config.Config{
+		Level: "info",
+		File:  config.FileConf{Path: b.TempDir()},
+		Sinks: []config.Sink{{Name: "bench", Type: "file", Format: "json"}},
+	})
+	b.Cleanup(func() { _ = l.Close() })
+	return log.With(l)
+}
+
+func BenchmarkKeyvals(b *testing.B) {
+	logger := benchmarkLogger(b)
+	users, err := []string{"alice", "bob"}, errors.New("boom")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = logger.Log(log.LevelInfo, "msg", "request", "user", users[i%2], "n", int64(i),
+			"took", time.Duration(i), "error", err)
+	}
+}
+
+func BenchmarkFields(b *testing.B) {
+	logger := benchmarkLogger(b)
+	users, err := []string{"alice", "bob"}, errors.New("boom")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = logger.Log(log.LevelInfo, "msg", "request", log.String("user", users[i%2]), log.Int64("n", int64(i)),
+			log.Duration("took", time.Duration(i)), log.Err(err))
+	}
+}
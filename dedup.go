@@ -0,0 +1,178 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDedupWindow is how long a Dedup suppresses repeats of an entry.
+const DefaultDedupWindow = time.Second
+
+// Keys of the summary entry logged by a Dedup.
+const (
+	RepeatedKey = "repeated"
+	FirstTsKey  = "first_ts"
+	LastTsKey   = "last_ts"
+)
+
+// DedupOption is Dedup option.
+type DedupOption func(*Dedup)
+
+// DedupWindow sets how long repeats are suppressed after an entry is logged.
+func DedupWindow(d time.Duration) DedupOption {
+	return func(o *Dedup) {
+		if d > 0 {
+			o.window = d
+		}
+	}
+}
+
+// DedupIgnore leaves keys out when comparing entries, e.g. volatile ones as
+// DedupIgnore("ts", "caller").
+func DedupIgnore(keys ...string) DedupOption {
+	return func(o *Dedup) {
+		for _, key := range keys {
+			o.ignore[key] = struct{}{}
+		}
+	}
+}
+
+// Dedup is a Logger suppressing the entries identical to one logged within
+// the window: same level, message and fields. When the window closes, an entry
+// that was repeated is logged once more with RepeatedKey, the number of
+// suppressed repeats, and the times of the first and last one.
+type Dedup struct {
+	logger Logger
+	window time.Duration
+	ignore map[string]struct{}
+
+	mu      sync.Mutex
+	entries map[string]*dedupEntry
+}
+
+type dedupEntry struct {
+	level    Level
+	keyvals  []interface{}
+	repeated int
+	first    time.Time
+	last     time.Time
+	timer    *time.Timer
+	force    bool // logged by ForceLog, so is the summary
+}
+
+// NewDedup returns a Dedup wrapping logger.
+func NewDedup(logger Logger, opts ...DedupOption) *Dedup {
+	d := &Dedup{
+		logger:  logger,
+		window:  DefaultDedupWindow,
+		ignore:  make(map[string]struct{}),
+		entries: make(map[string]*dedupEntry),
+	}
+	for _, o := range opts {
+		o(d)
+	}
+	return d
+}
+
+// Log passes the entry on unless it repeats one logged within the window.
+func (d *Dedup) Log(level Level, keyvals ...interface{}) error {
+	return d.log(level, false, keyvals)
+}
+
+// ForceLog is Log whatever the level of the wrapped Logger.
+func (d *Dedup) ForceLog(level Level, keyvals ...interface{}) error {
+	return d.log(level, true, keyvals)
+}
+
+func (d *Dedup) log(level Level, force bool, keyvals []interface{}) error {
+	keyvals = NormalizeKeyvals(keyvals)
+	key := d.key(level, keyvals)
+	now := time.Now()
+	d.mu.Lock()
+	if e, ok := d.entries[key]; ok {
+		e.repeated++
+		e.last = now
+		d.mu.Unlock()
+		return nil
+	}
+	e := &dedupEntry{
+		level:   level,
+		keyvals: append([]interface{}(nil), keyvals...),
+		first:   now,
+		last:    now,
+		force:   force,
+	}
+	e.timer = time.AfterFunc(d.window, func() {
+		d.close(key, e)
+	})
+	d.entries[key] = e
+	d.mu.Unlock()
+	return logTo(d.logger, force, level, keyvals)
+}
+
+// SetLevel sets the level of the wrapped Logger.
+func (d *Dedup) SetLevel(level string) {
+	d.logger.SetLevel(level)
+}
+
+// Enabled reports whether the wrapped Logger is enabled for level.
+func (d *Dedup) Enabled(level Level) bool {
+	return d.logger.Enabled(level)
+}
+
+// Flush closes every window now, logging the pending summaries, e.g. before
+// the program exits.
+func (d *Dedup) Flush() {
+	d.mu.Lock()
+	entries := d.entries
+	d.entries = make(map[string]*dedupEntry)
+	d.mu.Unlock()
+	for _, e := range entries {
+		e.timer.Stop()
+		d.summarize(e)
+	}
+}
+
+// close ends the window of e, unless Flush did. Whoever removes an entry
+// from d.entries logs its summary.
+func (d *Dedup) close(key string, e *dedupEntry) {
+	d.mu.Lock()
+	if d.entries[key] != e {
+		d.mu.Unlock()
+		return
+	}
+	delete(d.entries, key)
+	d.mu.Unlock()
+	d.summarize(e)
+}
+
+func (d *Dedup) summarize(e *dedupEntry) {
+	d.mu.Lock()
+	repeated, first, last := e.repeated, e.first, e.last
+	d.mu.Unlock()
+	if repeated == 0 {
+		return
+	}
+	kvs := append(e.keyvals, RepeatedKey, repeated, FirstTsKey, first, LastTsKey, last)
+	_ = logTo(d.logger, e.force, e.level, kvs)
+}
+
+// key identifies the entry by level and fields, without the ignored keys.
+// keyvals are pairs with string keys.
+func (d *Dedup) key(level Level, keyvals []interface{}) string {
+	var b strings.Builder
+	b.WriteString(level.String())
+	for i := 0; i < len(keyvals); i += 2 {
+		k := keyvals[i].(string)
+		if _, ok := d.ignore[k]; ok {
+			continue
+		}
+		b.WriteByte(0)
+		b.WriteString(k)
+		b.WriteByte('=')
+		fmt.Fprintf(&b, "%+v", keyvals[i+1])
+	}
+	return b.String()
+}
@@ -0,0 +1,44 @@
+package log_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	l "github.com/ysk229/go-logs"
+)
+
+func (r *recorder) len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+func TestDedup(t *testing.T) {
+	rec := &recorder{}
+	d := l.NewDedup(rec, l.DedupWindow(30*time.Millisecond), l.DedupIgnore("ts"))
+	for i := 0; i < 5; i++ {
+		_ = d.Log(l.LevelError, "ts", i, "msg", "db down", "host", "a")
+	}
+	_ = d.Log(l.LevelError, "ts", 9, "msg", "db down", "host", "b")
+	_ = d.Log(l.LevelWarn, "ts", 9, "msg", "db down", "host", "a")
+	assert.Equal(t, 3, rec.len())
+
+	assert.Eventually(t, func() bool { return rec.len() == 4 }, time.Second, 5*time.Millisecond)
+	rec.mu.Lock()
+	summary := rec.entries[3]
+	rec.mu.Unlock()
+	assert.Equal(t, []interface{}{l.LevelError, "ts", 0, "msg", "db down", "host", "a", "repeated", 4, "first_ts"}, summary[:10])
+	first, last := summary[10].(time.Time), summary[12].(time.Time)
+	assert.False(t, last.Before(first))
+
+	// after the window the entry is logged again
+	_ = d.Log(l.LevelError, "ts", 10, "msg", "db down", "host", "a")
+	_ = d.Log(l.LevelError, "ts", 11, "msg", "db down", "host", "a")
+	assert.Equal(t, 5, rec.len())
+	d.Flush()
+	assert.Equal(t, 6, rec.len())
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 6, rec.len(), "flushed windows are not summarized twice")
+}
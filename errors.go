@@ -0,0 +1,180 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrorDetail is what loggers log for an error: its message, the types of
+// the errors of its chain, its stack trace and, for a multi-error, the
+// errors it holds. JSON encodes it as a nested object, text prints the
+// message followed by an indented block (see Text).
+type ErrorDetail struct {
+	Message string `json:"msg"`
+	// Types holds the type of the error and of each error it wraps, following
+	// errors.Unwrap, outermost first.
+	Types []string `json:"types"`
+	// Stack is the deepest stack trace of the chain, from a StackTrace method
+	// as in github.com/pkg/errors or else from %+v formatting.
+	Stack string `json:"stack,omitempty"`
+	// Errors holds the errors of a multi-error, one with an Unwrap() []error
+	// or an Errors() []error method.
+	Errors []ErrorDetail `json:"errors,omitempty"`
+}
+
+// NewErrorDetail returns the ErrorDetail of err. A nil pointer whose Error
+// method panics has the message "<nil>", as fmt prints it.
+func NewErrorDetail(err error) ErrorDetail {
+	d := ErrorDetail{Message: errorString(err)}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		d.Types = append(d.Types, fmt.Sprintf("%T", e))
+		if nilPointer(e) {
+			break // its other methods may dereference it too
+		}
+		if st := stackTrace(e); st != "" {
+			d.Stack = st
+		}
+		if errs := multiErrors(e); errs != nil {
+			for _, e := range errs {
+				if e != nil {
+					d.Errors = append(d.Errors, NewErrorDetail(e))
+				}
+			}
+			break
+		}
+	}
+	if d.Stack == "" && !nilPointer(err) {
+		d.Stack = formattedStack(err)
+	}
+	return d
+}
+
+// errorString returns err.Error(), or "<nil>" when err is a nil pointer
+// whose Error method panics.
+func errorString(err error) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			if !nilPointer(err) {
+				panic(r)
+			}
+			s = "<nil>"
+		}
+	}()
+	return err.Error()
+}
+
+// nilPointer reports whether err holds a nil pointer.
+func nilPointer(err error) bool {
+	v := reflect.ValueOf(err)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// String returns the message, so %v prints an ErrorDetail as the error.
+func (d ErrorDetail) String() string {
+	return d.Message
+}
+
+// Text returns the indented block text formats print after the entry line,
+// each line starting with a newline and indented by four spaces, e.g. for
+// key "error":
+//
+//	error.types: *fmt.wrapError, *errors.errorString
+//	error.stack:
+//	    main.main
+//	    	/app/main.go:12
+//
+// It is empty for an error that wraps none and has neither stack nor errors.
+func (d ErrorDetail) Text(key string) string {
+	var b strings.Builder
+	d.writeText(&b, key, "    ")
+	return b.String()
+}
+
+func (d ErrorDetail) writeText(b *strings.Builder, key, indent string) {
+	if len(d.Types) > 1 {
+		fmt.Fprintf(b, "\n%s%s.types: %s", indent, key, strings.Join(d.Types, ", "))
+	}
+	if d.Stack != "" {
+		writeStack(b, key+".stack", d.Stack, indent)
+	}
+	for i, e := range d.Errors {
+		k := fmt.Sprintf("%s.errors[%d]", key, i)
+		fmt.Fprintf(b, "\n%s%s: %s", indent, k, e.Message)
+		e.writeText(b, k, indent+"    ")
+	}
+}
+
+// hasText reports whether Text is not empty.
+func (d ErrorDetail) hasText() bool {
+	return len(d.Types) > 1 || d.Stack != "" || len(d.Errors) > 0
+}
+
+// TextBlocks returns the text blocks of the ErrorDetail and Stack values of
+// keyvals, for text formats to print after the entry line.
+func TextBlocks(keyvals ...interface{}) string {
+	var b strings.Builder
+	for i := 1; i < len(keyvals); i += 2 {
+		switch v := keyvals[i].(type) {
+		case ErrorDetail:
+			if v.hasText() {
+				b.WriteString(v.Text(fmt.Sprint(keyvals[i-1])))
+			}
+		case Stack:
+			b.WriteString(v.Text(fmt.Sprint(keyvals[i-1])))
+		}
+	}
+	return b.String()
+}
+
+func multiErrors(err error) []error {
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		return e.Unwrap()
+	case interface{ Errors() []error }:
+		return e.Errors()
+	}
+	return nil
+}
+
+// stackTrace returns the %+v formatting of the result of the StackTrace
+// method of err, if any, e.g. the errors.StackTrace of github.com/pkg/errors.
+func stackTrace(err error) string {
+	if nilPointer(err) {
+		return ""
+	}
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return ""
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%+v", m.Call(nil)[0].Interface()), "\n")
+}
+
+// formattedStack returns what %+v prints after the message of err, the stack
+// trace of errors implementing fmt.Formatter that way.
+func formattedStack(err error) string {
+	if _, ok := err.(fmt.Formatter); !ok {
+		return ""
+	}
+	msg, verbose := err.Error(), fmt.Sprintf("%+v", err)
+	if verbose == msg || !strings.HasPrefix(verbose, msg) {
+		return ""
+	}
+	return strings.Trim(verbose[len(msg):], "\n")
+}
+
+// errorValues replaces the errors and the Err fields among the values of
+// kvs, pairs, with their ErrorDetail.
+func errorValues(kvs []interface{}) {
+	for i := 1; i < len(kvs); i += 2 {
+		switch v := kvs[i].(type) {
+		case error:
+			kvs[i] = NewErrorDetail(v)
+		case *ErrorField:
+			if v.Val != nil {
+				kvs[i] = NewErrorDetail(v.Val)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package log_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	l "github.com/ysk229/go-logs"
+)
+
+type multiError []error
+
+func (m multiError) Error() string   { return fmt.Sprintf("%d errors", len(m)) }
+func (m multiError) Errors() []error { return m }
+
+type ptrError struct{ msg string }
+
+func (e *ptrError) Error() string { return e.msg }
+
+func TestErrorDetailNilPointer(t *testing.T) {
+	var nilErr *ptrError
+	d := l.NewErrorDetail(nilErr)
+	assert.Equal(t, "<nil>", d.Message)
+	assert.Equal(t, []string{"*log_test.ptrError"}, d.Types)
+
+	d = l.NewErrorDetail(fmt.Errorf("load: %w", nilErr))
+	assert.Equal(t, "load: <nil>", d.Message)
+	assert.Equal(t, []string{"*fmt.wrapError", "*log_test.ptrError"}, d.Types)
+
+	rec := &recorder{}
+	assert.NotPanics(t, func() {
+		_ = l.With(rec).Log(l.LevelInfo, "err", error(nilErr), l.Err(nilErr))
+	})
+	assert.Equal(t, "<nil>", rec.entries[0][2].(l.ErrorDetail).Message)
+	assert.Equal(t, "<nil>", rec.entries[0][4].(l.ErrorDetail).Message)
+}
+
+func TestErrorDetail(t *testing.T) {
+	base := errors.New("boom")
+	d := l.NewErrorDetail(fmt.Errorf("load: %w", base))
+	assert.Equal(t, "load: boom", d.Message)
+	assert.Equal(t, []string{"*fmt.wrapError", "*errors.errorString"}, d.Types)
+	assert.Empty(t, d.Stack)
+	assert.Equal(t, "load: boom", fmt.Sprint(d))
+	assert.Equal(t, "\n    error.types: *fmt.wrapError, *errors.errorString", d.Text("error"))
+
+	d = l.NewErrorDetail(pkgerrors.Wrap(pkgerrors.New("boom"), "load"))
+	assert.Equal(t, "load: boom", d.Message)
+	assert.Equal(t, []string{"*errors.withStack", "*errors.withMessage", "*errors.fundamental"}, d.Types)
+	assert.True(t, strings.HasPrefix(d.Stack, "github.com/ysk229/go-logs_test.TestErrorDetail\n\t"), d.Stack)
+	assert.Contains(t, d.Text("err"), "\n    err.stack:\n        github.com/ysk229/go-logs_test.TestErrorDetail\n        \t")
+
+	d = l.NewErrorDetail(fmt.Errorf("batch: %w", multiError{base, fmt.Errorf("b: %w", base)}))
+	assert.Equal(t, []string{"*fmt.wrapError", "log_test.multiError"}, d.Types)
+	assert.Len(t, d.Errors, 2)
+	b, err := json.Marshal(d)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"msg":"batch: 2 errors","types":["*fmt.wrapError","log_test.multiError"],"errors":[
+		{"msg":"boom","types":["*errors.errorString"]},
+		{"msg":"b: boom","types":["*fmt.wrapError","*errors.errorString"]}]}`, string(b))
+	assert.Equal(t, "\n    error.types: *fmt.wrapError, log_test.multiError"+
+		"\n    error.errors[0]: boom"+
+		"\n    error.errors[1]: b: boom"+
+		"\n        error.errors[1].types: *fmt.wrapError, *errors.errorString", d.Text("error"))
+}
+
+func TestErrorValues(t *testing.T) {
+	rec := &recorder{}
+	err := errors.New("boom")
+	_ = l.With(rec, "cause", err).Log(l.LevelError, "msg", "failed", err, l.Err(err))
+	want := l.NewErrorDetail(err)
+	assert.Equal(t, []interface{}{l.LevelError, "cause", want, "msg", "failed", "error", want, "error", want}, rec.entries[0])
+	assert.Equal(t, "\n    cause.types: a, a\n    e.types: a, a",
+		l.TextBlocks("cause", l.ErrorDetail{Types: []string{"a", "a"}}, "x", 1, "e", l.ErrorDetail{Types: []string{"a", "a"}}, "f", want))
+}
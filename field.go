@@ -0,0 +1,155 @@
+package log
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrorKey is the key of the field made by Err.
+const ErrorKey = "error"
+
+// Field is a typed key/value pair made by String, Int64, Duration, Time, Err
+// or Any. It can be passed in place of a key and a value to Log and the *w
+// methods, e.g. Infow(log.String("user", u), "n", 1), and lets backends such
+// as contrib/zap encode the value without reflection. Other backends log
+// Value.
+//
+// Each Field is a pointer to the few words it holds, so passing it as an
+// interface{} does not copy it to the heap again: a Field costs one small
+// allocation where a key and a value boxed apart may cost two.
+type Field interface {
+	// Key returns the key of the field.
+	Key() string
+	// Value returns the value of the field.
+	Value() interface{}
+	fmt.Formatter
+	boxedKey() interface{}
+}
+
+// fieldKey is the key of a Field, boxed once by the constructor, which is
+// free for a constant key.
+type fieldKey struct{ key interface{} }
+
+// Key returns the key of the field.
+func (k fieldKey) Key() string {
+	s, _ := k.key.(string)
+	return s
+}
+
+func (k fieldKey) boxedKey() interface{} {
+	if k.key == nil {
+		return ""
+	}
+	return k.key
+}
+
+// StringField is the Field made by String.
+type StringField struct {
+	fieldKey
+	Val string
+}
+
+// Int64Field is the Field made by Int64.
+type Int64Field struct {
+	fieldKey
+	Val int64
+}
+
+// DurationField is the Field made by Duration.
+type DurationField struct {
+	fieldKey
+	Val time.Duration
+}
+
+// TimeField is the Field made by Time.
+type TimeField struct {
+	fieldKey
+	Val time.Time
+}
+
+// ErrorField is the Field made by Err.
+type ErrorField struct {
+	fieldKey
+	Val error
+}
+
+// AnyField is the Field made by Any.
+type AnyField struct {
+	fieldKey
+	Val interface{}
+}
+
+// String returns a Field holding a string.
+func String(key, val string) Field {
+	return &StringField{fieldKey{key}, val}
+}
+
+// Int64 returns a Field holding an int64.
+func Int64(key string, val int64) Field {
+	return &Int64Field{fieldKey{key}, val}
+}
+
+// Duration returns a Field holding a time.Duration.
+func Duration(key string, val time.Duration) Field {
+	return &DurationField{fieldKey{key}, val}
+}
+
+// Time returns a Field holding a time.Time, kept as is so that the zero time
+// and the times out of the range of unix nanoseconds keep their value.
+func Time(key string, val time.Time) Field {
+	return &TimeField{fieldKey{key}, val}
+}
+
+// Err returns a Field holding err under ErrorKey.
+func Err(err error) Field {
+	return &ErrorField{fieldKey{ErrorKey}, err}
+}
+
+// Any returns a Field holding any value.
+func Any(key string, val interface{}) Field {
+	return &AnyField{fieldKey{key}, val}
+}
+
+// Value returns the value of f.
+func (f *StringField) Value() interface{} { return f.Val }
+
+// Value returns the value of f.
+func (f *Int64Field) Value() interface{} { return f.Val }
+
+// Value returns the value of f.
+func (f *DurationField) Value() interface{} { return f.Val }
+
+// Value returns the value of f.
+func (f *TimeField) Value() interface{} { return f.Val }
+
+// Value returns the value of f.
+func (f *ErrorField) Value() interface{} { return f.Val }
+
+// Value returns the value of f.
+func (f *AnyField) Value() interface{} { return f.Val }
+
+// Format implements fmt.Formatter, so a Field prints as its value.
+func (f *StringField) Format(s fmt.State, verb rune) { formatValue(s, verb, f.Val) }
+
+// Format implements fmt.Formatter, so a Field prints as its value.
+func (f *Int64Field) Format(s fmt.State, verb rune) { formatValue(s, verb, f.Val) }
+
+// Format implements fmt.Formatter, so a Field prints as its value.
+func (f *DurationField) Format(s fmt.State, verb rune) { formatValue(s, verb, f.Val) }
+
+// Format implements fmt.Formatter, so a Field prints as its value.
+func (f *TimeField) Format(s fmt.State, verb rune) { formatValue(s, verb, f.Val) }
+
+// Format implements fmt.Formatter, so a Field prints as its value.
+func (f *ErrorField) Format(s fmt.State, verb rune) { formatValue(s, verb, f.Val) }
+
+// Format implements fmt.Formatter, so a Field prints as its value.
+func (f *AnyField) Format(s fmt.State, verb rune) { formatValue(s, verb, f.Val) }
+
+func formatValue(s fmt.State, verb rune, v interface{}) {
+	format := "%" + string(verb)
+	if s.Flag('+') {
+		format = "%+" + string(verb)
+	}
+	fmt.Fprintf(s, format, v)
+}
@@ -0,0 +1,82 @@
+package log_test
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	l "github.com/ysk229/go-logs"
+)
+
+func TestField(t *testing.T) {
+	ts := time.Date(2022, 9, 1, 10, 0, 0, 0, time.UTC)
+	far := time.Date(3000, 1, 1, 0, 0, 0, 0, time.FixedZone("X", 3600)) // out of the unix nano range
+	err := errors.New("boom")
+	tests := []struct {
+		field l.Field
+		key   string
+		value interface{}
+	}{
+		{l.String("user", "bob"), "user", "bob"},
+		{l.Int64("n", 42), "n", int64(42)},
+		{l.Duration("took", time.Second), "took", time.Second},
+		{l.Time("at", ts), "at", ts},
+		{l.Time("zero", time.Time{}), "zero", time.Time{}},
+		{l.Time("far", far), "far", far},
+		{l.Err(err), "error", err},
+		{l.Any("tags", []string{"a"}), "tags", []string{"a"}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.key, tt.field.Key())
+		assert.Equal(t, tt.value, tt.field.Value())
+		assert.Equal(t, fmt.Sprint(tt.value), fmt.Sprint(tt.field))
+	}
+}
+
+func TestFieldKeyvals(t *testing.T) {
+	rec := &recorder{}
+	n := l.Int64("n", 1)
+	logger := l.With(rec, l.String("app", "test"))
+	_ = logger.Log(l.LevelInfo, "msg", "typed", n, "user", "bob", &l.StringField{Val: "x"})
+
+	e := rec.entries[0]
+	assert.Equal(t, []interface{}{"app", l.String("app", "test")}, e[1:3])
+	assert.Equal(t, []interface{}{"msg", "typed", "n", n, "user", "bob", ""}, e[3:10])
+
+	// a Field in place of a value is left alone
+	_ = logger.Log(l.LevelInfo, "n", n)
+	assert.Equal(t, []interface{}{"n", n}, rec.entries[1][3:])
+}
+
+func TestFilterField(t *testing.T) {
+	rec := &recorder{}
+	f := l.NewFilter(rec, l.FilterKey("password"), l.FilterPattern(regexp.MustCompile(`\d{4}-\d{4}`)))
+	_ = l.With(f).Log(l.LevelInfo, l.String("password", "secret"), l.String("card", "1234-5678"), l.Int64("n", 1))
+	assert.Equal(t, []interface{}{l.LevelInfo, "password", "***", "card", "***", "n", l.Int64("n", 1)}, rec.entries[0])
+}
+
+func TestWrappersField(t *testing.T) {
+	// Filter, Sampler and Dedup see the pairs of the entry, not its raw keyvals
+	rec := &recorder{}
+	f := l.NewFilter(rec, l.FilterKey("password"))
+	_ = f.Log(l.LevelInfo, l.String("password", "secret"), "email", "a@b.com")
+	assert.Equal(t, []interface{}{l.LevelInfo, "password", "***", "email", "a@b.com"}, rec.entries[0])
+
+	rec = &recorder{}
+	s := l.NewSampler(rec, l.SampleTick(time.Hour), l.SampleLevel(l.LevelInfo, 1, 0))
+	_ = s.Log(l.LevelInfo, l.String("msg", "a"))
+	_ = s.Log(l.LevelInfo, l.String("msg", "b"))
+	_ = s.Log(l.LevelInfo, l.String("msg", "b"))
+	assert.Len(t, rec.entries, 2)
+
+	rec = &recorder{}
+	d := l.NewDedup(rec, l.DedupWindow(time.Hour), l.DedupIgnore("ts"))
+	_ = d.Log(l.LevelError, l.Int64("ts", 1), "msg", "db down")
+	_ = d.Log(l.LevelError, l.Int64("ts", 2), "msg", "db down")
+	assert.Len(t, rec.entries, 1)
+	d.Flush()
+}
@@ -6,6 +6,8 @@ import (
 
 	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
 	"github.com/natefinch/lumberjack"
+
+	"github.com/ysk229/go-logs/config"
 )
 
 // LogOption is fileLog option.
@@ -29,6 +31,24 @@ func NewFileLog(name string, opts ...LogOption) *Log {
 	return &options
 }
 
+// Options returns the options set in conf.
+func Options(conf config.FileConf) []LogOption {
+	var opts []LogOption
+	if len(conf.Path) > 0 {
+		opts = append(opts, Path(conf.Path))
+	}
+	if len(conf.Mode) > 0 {
+		opts = append(opts, Mode(conf.Mode))
+	}
+	if conf.MaxAge > 0 {
+		opts = append(opts, MaxAge(conf.MaxAge))
+	}
+	if conf.Size > 0 {
+		opts = append(opts, Size(conf.Size))
+	}
+	return opts
+}
+
 // Mode file mode
 func Mode(mode string) LogOption {
 	return func(opts *Log) {
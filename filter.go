@@ -0,0 +1,250 @@
+package log
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// FilterMask replaces the values masked by a Filter.
+const FilterMask = "***"
+
+// FilterOption is Filter option.
+type FilterOption func(*Filter)
+
+// FilterLevel drops the entries below level.
+func FilterLevel(level Level) FilterOption {
+	return func(f *Filter) {
+		f.level = level
+	}
+}
+
+// FilterKey masks the values of the given keys, compared case insensitively,
+// e.g. FilterKey("password", "token", "authorization").
+func FilterKey(keys ...string) FilterOption {
+	return func(f *Filter) {
+		for _, key := range keys {
+			f.keys[strings.ToLower(key)] = struct{}{}
+		}
+	}
+}
+
+// FilterPattern masks the parts of string values matching any pattern. The
+// values holding others, slices, maps and structs, are matched and logged as
+// their %v rendering.
+func FilterPattern(patterns ...*regexp.Regexp) FilterOption {
+	return func(f *Filter) {
+		f.patterns = append(f.patterns, patterns...)
+	}
+}
+
+// FilterMessageKey sets the key of the message, which RedactDrop scrubs
+// instead of dropping, MessageKey by default.
+func FilterMessageKey(key string) FilterOption {
+	return func(f *Filter) {
+		f.msgKey = key
+	}
+}
+
+// FilterFunc drops the entries for which f returns true. It is called with
+// the fields of the wrapped logger's With as well as with the entry.
+func FilterFunc(f func(level Level, keyvals ...interface{}) bool) FilterOption {
+	return func(o *Filter) {
+		o.filter = f
+	}
+}
+
+// Filter is a Logger dropping and masking entries before they reach the
+// wrapped Logger, whatever its backend. Wrap it with With or WithContext as
+// any other Logger. The fields the wrapped Logger got from With are masked
+// when the Filter is made, the values their Valuers return are not.
+type Filter struct {
+	logger   Logger
+	level    Level
+	keys     map[string]struct{}
+	patterns []*regexp.Regexp
+	pii      *piiScrubber
+	filter   func(level Level, keyvals ...interface{}) bool
+	msgKey   string
+}
+
+// NewFilter returns a Filter wrapping logger.
+func NewFilter(logger Logger, opts ...FilterOption) *Filter {
+	f := &Filter{
+		logger: logger,
+		level:  LevelTrace,
+		keys:   make(map[string]struct{}),
+		msgKey: MessageKey,
+	}
+	for _, o := range opts {
+		o(f)
+	}
+	f.maskPrefix()
+	return f
+}
+
+// maskPrefix masks the fields the wrapped logger got from With, once.
+func (f *Filter) maskPrefix() {
+	c, ok := f.logger.(*logger)
+	if !ok || len(c.prefix) == 0 {
+		return
+	}
+	masked := *c
+	masked.prefix = f.mask(c.prefix)
+	masked.hasValuer = containsValuer(masked.prefix)
+	f.logger = &masked
+}
+
+// Log drops or masks the entry, then passes it on. keyvals is not modified.
+func (f *Filter) Log(level Level, keyvals ...interface{}) error {
+	if level < f.level {
+		return nil
+	}
+	return f.log(level, false, keyvals)
+}
+
+// ForceLog is Log whatever the level of the wrapped Logger, the level of f
+// still applies.
+func (f *Filter) ForceLog(level Level, keyvals ...interface{}) error {
+	if level < f.level {
+		return nil
+	}
+	return f.log(level, true, keyvals)
+}
+
+func (f *Filter) log(level Level, force bool, keyvals []interface{}) error {
+	keyvals = NormalizeKeyvals(keyvals) // a Field or an error in place of a key shifts the pairs otherwise
+	if f.filter != nil {
+		if c, ok := f.logger.(*logger); ok && len(c.prefix) > 0 && f.filter(level, c.prefix...) {
+			return nil
+		}
+		if f.filter(level, keyvals...) {
+			return nil
+		}
+	}
+	return logTo(f.logger, force, level, f.mask(keyvals))
+}
+
+// SetLevel sets the level of the wrapped Logger.
+func (f *Filter) SetLevel(level string) {
+	f.logger.SetLevel(level)
+}
+
+// Enabled reports whether level passes the level of f and of the wrapped Logger.
+func (f *Filter) Enabled(level Level) bool {
+	return level >= f.level && f.logger.Enabled(level)
+}
+
+// mask returns keyvals, pairs, with the filtered values masked or dropped,
+// copied on the first change.
+func (f *Filter) mask(keyvals []interface{}) []interface{} {
+	if len(f.keys) == 0 && len(f.patterns) == 0 && f.pii == nil {
+		return keyvals
+	}
+	var out []interface{}
+	for i := 1; i < len(keyvals); i += 2 {
+		v, changed, drop := f.maskValue(keyvals[i-1], keyvals[i])
+		if out == nil {
+			if !changed && !drop {
+				continue
+			}
+			out = append(make([]interface{}, 0, len(keyvals)), keyvals[:i-1]...)
+		}
+		if !drop {
+			out = append(out, keyvals[i-1], v)
+		}
+	}
+	if out == nil {
+		return keyvals
+	}
+	return out
+}
+
+// maskValue returns the value to log for key and whether it changed or the
+// field is to be dropped.
+func (f *Filter) maskValue(key, value interface{}) (v interface{}, changed, drop bool) {
+	k, _ := key.(string)
+	if _, ok := f.keys[strings.ToLower(k)]; ok {
+		return FilterMask, true, false
+	}
+	if len(f.patterns) == 0 && f.pii == nil {
+		return value, false, false
+	}
+	raw := value
+	if fd, ok := value.(Field); ok {
+		raw = fd.Value()
+	}
+	var s string
+	switch v := raw.(type) {
+	case string:
+		s = v
+	case ErrorDetail:
+		d, changed, drop := f.maskError(v)
+		return d, changed, drop
+	case error:
+		s = errorString(v)
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		if !composite(raw) {
+			return value, false, false
+		}
+		s = fmt.Sprintf("%v", raw)
+	}
+	masked, drop := f.scrub(s, k != f.msgKey)
+	if drop {
+		return nil, false, true
+	}
+	if masked == s {
+		return value, false, false
+	}
+	return masked, true, false
+}
+
+// composite reports whether v is a slice, an array, a map or a struct, or a
+// pointer to one, which may hold strings.
+func composite(v interface{}) bool {
+	switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		return true
+	}
+	return false
+}
+
+// maskError masks the messages of d and of the errors it holds.
+func (f *Filter) maskError(d ErrorDetail) (ErrorDetail, bool, bool) {
+	masked, drop := f.scrub(d.Message, true)
+	if drop {
+		return d, false, true
+	}
+	changed := masked != d.Message
+	d.Message = masked
+	if len(d.Errors) > 0 {
+		errs := make([]ErrorDetail, len(d.Errors))
+		for i, e := range d.Errors {
+			m, c, drop := f.maskError(e)
+			if drop {
+				return d, false, true
+			}
+			errs[i], changed = m, changed || c
+		}
+		d.Errors = errs
+	}
+	return d, changed, false
+}
+
+// scrub returns s with the patterns and the personal data masked, and
+// whether the field is to be dropped, droppable telling whether it can be.
+func (f *Filter) scrub(s string, droppable bool) (string, bool) {
+	for _, p := range f.patterns {
+		s = p.ReplaceAllString(s, FilterMask)
+	}
+	if f.pii != nil {
+		var found bool
+		if s, found = f.pii.scrub(s); found && f.pii.redaction == RedactDrop && droppable {
+			return "", true
+		}
+	}
+	return s, false
+}
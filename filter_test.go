@@ -0,0 +1,77 @@
+package log_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	l "github.com/ysk229/go-logs"
+)
+
+func TestFilter(t *testing.T) {
+	rec := &recorder{}
+	f := l.NewFilter(rec,
+		l.FilterLevel(l.LevelInfo),
+		l.FilterKey("password", "Authorization"),
+		l.FilterPattern(regexp.MustCompile(`\d{4}-\d{4}`)),
+		l.FilterFunc(func(level l.Level, keyvals ...interface{}) bool {
+			for i := 0; i < len(keyvals); i++ {
+				if keyvals[i] == "healthz" {
+					return true
+				}
+			}
+			return false
+		}),
+	)
+
+	kvs := []interface{}{"msg", "login", "password", "secret", "authorization", "Bearer x", "card", "1234-5678 ok"}
+	_ = f.Log(l.LevelInfo, kvs...)
+	assert.Equal(t, []interface{}{l.LevelInfo, "msg", "login", "password", "***", "authorization", "***", "card", "*** ok"}, rec.entries[0])
+	assert.Equal(t, "secret", kvs[3], "keyvals must not be modified")
+
+	assert.False(t, f.Enabled(l.LevelDebug))
+	assert.True(t, f.Enabled(l.LevelInfo))
+	_ = f.Log(l.LevelDebug, "msg", "debug")
+	_ = f.Log(l.LevelWarn, "path", "healthz")
+	assert.Len(t, rec.entries, 1)
+
+	_ = f.Log(l.LevelError, "err", errors.New("card 1111-2222 declined"))
+	assert.Equal(t, []interface{}{l.LevelError, "err", "card *** declined"}, rec.entries[1])
+	// the core logger passes errors as ErrorDetail, masked the same way
+	_ = l.With(f).Log(l.LevelError, fmt.Errorf("charge 3333-4444: %w", multiError{errors.New("card 1111-2222 declined")}))
+	d := rec.entries[2][2].(l.ErrorDetail)
+	assert.Equal(t, "charge ***: 1 errors", d.Message)
+	assert.Equal(t, "card *** declined", d.Errors[0].Message)
+	rec.entries = rec.entries[:2]
+
+	// fields added by With and WithContext are filtered as well
+	logger := l.With(f, "token", "t", "PASSWORD", "p")
+	logger = l.WithContext(context.Background(), logger)
+	_ = logger.Log(l.LevelInfo, "msg", "with")
+	assert.Equal(t, []interface{}{l.LevelInfo, "token", "t", "PASSWORD", "***", "msg", "with"}, rec.entries[2])
+	_ = l.With(f, "path", "healthz").Log(l.LevelInfo, "msg", "dropped")
+	assert.Len(t, rec.entries, 3)
+
+	// the filter func sees the fields of the wrapped logger
+	_ = l.NewFilter(l.With(rec, "path", "healthz"), l.FilterFunc(func(level l.Level, keyvals ...interface{}) bool {
+		return len(keyvals) > 1 && keyvals[1] == "healthz"
+	})).Log(l.LevelInfo, "msg", "dropped")
+	assert.Len(t, rec.entries, 3)
+
+	// so are the fields the wrapped logger got from With
+	_ = l.NewFilter(l.With(rec, "password", "p"), l.FilterKey("password")).Log(l.LevelInfo, "msg", "prefix")
+	assert.Equal(t, []interface{}{l.LevelInfo, "password", "***", "msg", "prefix"}, rec.entries[3])
+
+	// ForceLog passes the level of the wrapped logger, not the one of the filter
+	_ = l.ForceLog(f, l.LevelDebug, "msg", "forced debug")
+	assert.Len(t, rec.entries, 4)
+	_ = l.ForceLog(f, l.LevelInfo, "msg", "forced", "password", "secret")
+	assert.Equal(t, []interface{}{l.LevelInfo, "msg", "forced", "password", "***"}, rec.entries[4])
+
+	f.SetLevel("error")
+	assert.Equal(t, "error", rec.getLevel())
+}
@@ -0,0 +1,125 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type levelPayload struct {
+	Name   string            `json:"name,omitempty"`
+	Level  string            `json:"level,omitempty"`
+	Levels map[string]string `json:"levels,omitempty"`
+}
+
+type errorPayload struct {
+	Error string `json:"error"`
+}
+
+type levelHandler struct {
+	logger Logger
+}
+
+// LevelHandler returns an http.Handler to read and change the levels of l at
+// runtime, like zap's AtomicLevel.
+//
+// GET reports the default level and every named level:
+//
+//	{"level":"info","levels":{"db":"debug"}}
+//
+// GET ?name=db reports the level that applies to one name. PUT and POST change
+// a level, either from a JSON body {"name":"db","level":"debug"} or from the
+// form values name and level. Without a name the default level is changed.
+// Reading levels needs l to be a NamedLeveler, as the loggers built by With,
+// Named and the log package are.
+func LevelHandler(l Logger) http.Handler {
+	return &levelHandler{logger: l}
+}
+
+func (h *levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	nl, named := h.logger.(NamedLeveler)
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		req, err := decodeLevel(r)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorPayload{Error: err.Error()})
+			return
+		}
+		switch {
+		case named:
+			nl.SetNamedLevel(req.Name, req.Level)
+		case req.Name == "":
+			h.logger.SetLevel(req.Level)
+		default:
+			writeJSON(w, http.StatusBadRequest, errorPayload{Error: "logger has no named levels"})
+			return
+		}
+		if !named {
+			writeJSON(w, http.StatusOK, levelPayload{Level: strings.ToLower(req.Level)})
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		writeJSON(w, http.StatusMethodNotAllowed, errorPayload{Error: "only GET, PUT and POST are supported"})
+		return
+	}
+	if !named {
+		writeJSON(w, http.StatusNotImplemented, errorPayload{Error: "logger does not report its level"})
+		return
+	}
+	writeJSON(w, http.StatusOK, currentLevels(nl, r.URL.Query().Get("name")))
+}
+
+func decodeLevel(r *http.Request) (*levelPayload, error) {
+	req := &levelPayload{}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		req.Name, req.Level = r.FormValue("name"), r.FormValue("level")
+	} else if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		return nil, fmt.Errorf("malformed request body: %w", err)
+	}
+	if req.Name == "" {
+		req.Name = r.URL.Query().Get("name")
+	}
+	if req.Level == "" {
+		return nil, errors.New("level is required")
+	}
+	if _, err := ParseLevelE(req.Level); err != nil {
+		return nil, err
+	}
+	if req.Name == "*" {
+		req.Name = ""
+	}
+	return req, nil
+}
+
+func currentLevels(nl NamedLeveler, name string) levelPayload {
+	levels := nl.Levels()
+	if name != "" && name != "*" {
+		res := levelPayload{Name: name}
+		if level, ok := (&nameLevels{levels: levels}).get(name); ok {
+			res.Level = strings.ToLower(level.String())
+		}
+		return res
+	}
+	res := levelPayload{}
+	for n, level := range levels {
+		if n == "" {
+			res.Level = strings.ToLower(level.String())
+			continue
+		}
+		if res.Levels == nil {
+			res.Levels = make(map[string]string)
+		}
+		res.Levels[n] = strings.ToLower(level.String())
+	}
+	return res
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
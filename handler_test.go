@@ -0,0 +1,73 @@
+package log_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	l "github.com/ysk229/go-logs"
+)
+
+func TestLevelHandler(t *testing.T) {
+	rec := &recorder{}
+	root := l.With(rec)
+	root.SetLevel("info")
+	h := l.LevelHandler(root)
+
+	do := func(method, target, contentType, body string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		res := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		return w.Code, res
+	}
+
+	code, res := do(http.MethodGet, "/", "", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]interface{}{"level": "info"}, res)
+
+	code, res = do(http.MethodPut, "/", "application/json", `{"level":"warn"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "warn", res["level"])
+	assert.Equal(t, "warn", rec.getLevel())
+
+	form := url.Values{"name": {"db"}, "level": {"debug"}}.Encode()
+	code, res = do(http.MethodPost, "/", "application/x-www-form-urlencoded", form)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]interface{}{"level": "warn", "levels": map[string]interface{}{"db": "debug"}}, res)
+	assert.Equal(t, "debug", rec.getLevel())
+
+	code, res = do(http.MethodGet, "/?name=db.pool", "", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]interface{}{"name": "db.pool", "level": "debug"}, res)
+
+	code, res = do(http.MethodPut, "/?name=http", "", `{"level":"error"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]interface{}{"name": "http", "level": "error"}, res)
+
+	code, res = do(http.MethodPut, "/", "", `{"level":"loud"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, res["error"], "loud")
+
+	code, _ = do(http.MethodPut, "/", "", `{`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = do(http.MethodDelete, "/", "", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+
+	h = l.LevelHandler(rec)
+	code, _ = do(http.MethodGet, "/", "", "")
+	assert.Equal(t, http.StatusNotImplemented, code)
+	code, res = do(http.MethodPut, "/", "", `{"level":"error"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "error", rec.getLevel())
+}
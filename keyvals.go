@@ -0,0 +1,185 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// BadKey is the key logged for a value without a string key, e.g. the last
+// element of an odd-length keyvals.
+const BadKey = "!BADKEY"
+
+// ErrBadKeyvals is wrapped by the error Log returns in strict mode.
+var ErrBadKeyvals = errors.New("log: malformed keyvals")
+
+// Duplicates is how a logger resolves the keys an entry has more than once,
+// e.g. a key of With also passed to Log.
+type Duplicates string
+
+const (
+	// DupKeepAll passes every field on, the default.
+	DupKeepAll Duplicates = "keep_all"
+	// DupLastWins keeps the last value, at the position of the first field.
+	DupLastWins Duplicates = "last_wins"
+	// DupFirstWins keeps the first field.
+	DupFirstWins Duplicates = "first_wins"
+	// DupSuffix renames the repeats user_1, user_2...
+	DupSuffix Duplicates = "suffix"
+)
+
+// policy holds the settings shared by a logger and those made from it with
+// With, Named and WithContext.
+type policy struct {
+	strict     int32
+	duplicates atomic.Value // Duplicates
+}
+
+// SetDuplicates sets how l, and the loggers sharing its levels, resolve
+// duplicate keys. l must have been made with With, Named or WithContext.
+func SetDuplicates(l Logger, d Duplicates) {
+	if c, ok := l.(*logger); ok {
+		c.policy.duplicates.Store(d)
+	}
+}
+
+// SetStrict turns the strict mode of l, and of the loggers sharing its
+// levels, on or off. In strict mode an entry with malformed keyvals, its own
+// or those given to With, is still logged with BadKey, and Log returns an
+// error wrapping ErrBadKeyvals. l must have been made with With, Named or
+// WithContext.
+func SetStrict(l Logger, strict bool) {
+	c, ok := l.(*logger)
+	if !ok {
+		return
+	}
+	var v int32
+	if strict {
+		v = 1
+	}
+	atomic.StoreInt32(&c.policy.strict, v)
+}
+
+func (p *policy) isStrict() bool {
+	return atomic.LoadInt32(&p.strict) == 1
+}
+
+// resolve returns kvs, pairs with string keys, with the duplicate keys
+// resolved, copied when any is found.
+func (p *policy) resolve(kvs []interface{}) []interface{} {
+	d, _ := p.duplicates.Load().(Duplicates)
+	if d == "" || d == DupKeepAll || !hasDuplicate(kvs) {
+		return kvs
+	}
+	out := make([]interface{}, 0, len(kvs))
+	for i := 0; i < len(kvs); i += 2 {
+		switch d {
+		case DupFirstWins:
+			if indexKey(out, kvs[i]) < 0 {
+				out = append(out, kvs[i], kvs[i+1])
+			}
+		case DupLastWins:
+			if indexKey(out, kvs[i]) < 0 {
+				j := lastIndexKey(kvs, kvs[i])
+				out = append(out, kvs[i], kvs[j+1])
+			}
+		case DupSuffix:
+			key := kvs[i].(string)
+			for n := 1; indexKey(out, key) >= 0 || (key != kvs[i] && indexKey(kvs, key) >= 0); n++ {
+				key = fmt.Sprintf("%s_%d", kvs[i], n)
+			}
+			out = append(out, key, kvs[i+1])
+		default:
+			out = append(out, kvs[i], kvs[i+1])
+		}
+	}
+	return out
+}
+
+func hasDuplicate(kvs []interface{}) bool {
+	for i := 2; i < len(kvs); i += 2 {
+		if indexKey(kvs[:i], kvs[i]) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// indexKey returns the index of key among the keys of kvs, or -1.
+func indexKey(kvs []interface{}, key interface{}) int {
+	for i := 0; i < len(kvs); i += 2 {
+		if kvs[i] == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func lastIndexKey(kvs []interface{}, key interface{}) int {
+	for i := len(kvs) - 2; i >= 0; i -= 2 {
+		if kvs[i] == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// NormalizeKeyvals returns keyvals as key/value pairs with string keys, as
+// the loggers made with With, Named and WithContext pass them on. keyvals is
+// returned as is when it already is, so backends can apply the policy at no
+// cost to entries coming from those loggers.
+func NormalizeKeyvals(keyvals []interface{}) []interface{} {
+	if len(keyvals)%2 == 0 {
+		i := 0
+		for ; i < len(keyvals); i += 2 {
+			if _, ok := keyvals[i].(string); !ok {
+				break
+			}
+		}
+		if i == len(keyvals) {
+			return keyvals
+		}
+	}
+	return appendKeyvals(make([]interface{}, 0, len(keyvals)+2), keyvals)
+}
+
+// appendKeyvals appends keyvals to dst as key/value pairs: a Field in place
+// of a key becomes the pair of its key and itself, a value without a key
+// gets BadKey.
+func appendKeyvals(dst, keyvals []interface{}) []interface{} {
+	for i := 0; i < len(keyvals); i++ {
+		switch k := keyvals[i].(type) {
+		case string:
+			if i+1 == len(keyvals) {
+				return append(dst, BadKey, k)
+			}
+			dst = append(dst, k, keyvals[i+1])
+			i++
+		case error:
+			dst = append(dst, ErrorKey, k)
+		case Field:
+			dst = append(dst, k.boxedKey(), keyvals[i])
+		default:
+			dst = append(dst, BadKey, keyvals[i])
+		}
+	}
+	return dst
+}
+
+// checkKeyvals returns an error describing the first element of keyvals
+// that appendKeyvals gives BadKey.
+func checkKeyvals(keyvals []interface{}) error {
+	for i := 0; i < len(keyvals); i++ {
+		switch k := keyvals[i].(type) {
+		case string:
+			if i+1 == len(keyvals) {
+				return fmt.Errorf("%w: key %q has no value", ErrBadKeyvals, k)
+			}
+			i++
+		case Field, error:
+		default:
+			return fmt.Errorf("%w: key %d is a %T, not a string", ErrBadKeyvals, i, k)
+		}
+	}
+	return nil
+}
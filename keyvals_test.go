@@ -0,0 +1,102 @@
+package log_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	l "github.com/ysk229/go-logs"
+)
+
+func TestBadKey(t *testing.T) {
+	tests := map[string]struct {
+		kvs  []interface{}
+		want []interface{}
+		err  string
+	}{
+		"pairs":       {[]interface{}{"a", 1, "b", 2}, []interface{}{"a", 1, "b", 2}, ""},
+		"odd":         {[]interface{}{"a", 1, "b"}, []interface{}{"a", 1, l.BadKey, "b"}, `log: malformed keyvals: key "b" has no value`},
+		"non string":  {[]interface{}{"a", 1, 2, "b", 3}, []interface{}{"a", 1, l.BadKey, 2, "b", 3}, "log: malformed keyvals: key 2 is a int, not a string"},
+		"single":      {[]interface{}{3.5}, []interface{}{l.BadKey, 3.5}, "log: malformed keyvals: key 0 is a float64, not a string"},
+		"field":       {[]interface{}{l.Int64("n", 1), "a"}, []interface{}{"n", l.Int64("n", 1), l.BadKey, "a"}, `log: malformed keyvals: key "a" has no value`},
+		"nil key":     {[]interface{}{nil, 1}, []interface{}{l.BadKey, nil, l.BadKey, 1}, "log: malformed keyvals: key 0 is a <nil>, not a string"},
+		"field value": {[]interface{}{"n", l.Int64("n", 1)}, []interface{}{"n", l.Int64("n", 1)}, ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := &recorder{}
+			logger := l.With(rec)
+			assert.NoError(t, logger.Log(l.LevelInfo, tt.kvs...))
+
+			l.SetStrict(logger, true)
+			err := l.Named(logger, "child").Log(l.LevelInfo, tt.kvs...)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, l.ErrBadKeyvals)
+				assert.EqualError(t, err, tt.err)
+			}
+			assert.Equal(t, append([]interface{}{l.LevelInfo}, tt.want...), rec.entries[0])
+			assert.Len(t, rec.entries, 2, "strict mode logs the entry too")
+			assert.Equal(t, tt.want, l.NormalizeKeyvals(tt.kvs))
+		})
+	}
+}
+
+func TestBadKeyWith(t *testing.T) {
+	rec := &recorder{}
+	logger := l.With(rec, "a", 1, 2)
+	assert.NoError(t, logger.Log(l.LevelInfo, "msg", "m"))
+	assert.Equal(t, []interface{}{l.LevelInfo, "a", 1, l.BadKey, 2, "msg", "m"}, rec.entries[0])
+
+	// strict mode reports the malformed prefix to the children too
+	l.SetStrict(logger, true)
+	child := l.Named(l.With(logger, "b", 3), "child")
+	err := child.Log(l.LevelInfo, "msg", "m")
+	assert.ErrorIs(t, err, l.ErrBadKeyvals)
+	assert.EqualError(t, err, "log: malformed keyvals: key 2 is a int, not a string")
+	assert.Len(t, rec.entries, 2)
+	wellFormed := l.With(rec, "a", 1)
+	l.SetStrict(wellFormed, true)
+	assert.NoError(t, wellFormed.Log(l.LevelInfo, "msg", "m"))
+}
+
+func TestNormalizeKeyvals(t *testing.T) {
+	kvs := []interface{}{"a", 1}
+	got := l.NormalizeKeyvals(kvs)
+	assert.Equal(t, &kvs[0], &got[0], "well formed keyvals are not copied")
+}
+
+func TestDuplicates(t *testing.T) {
+	tests := map[l.Duplicates][]interface{}{
+		l.DupKeepAll:   {"user", "a", "id", 1, "user", "b", "user", "c"},
+		l.DupLastWins:  {"user", "c", "id", 1},
+		l.DupFirstWins: {"user", "a", "id", 1},
+		l.DupSuffix:    {"user", "a", "id", 1, "user_1", "b", "user_2", "c"},
+	}
+	for d, want := range tests {
+		t.Run(string(d), func(t *testing.T) {
+			rec := &recorder{}
+			logger := l.With(rec, "user", "a", "id", 1)
+			l.SetDuplicates(logger, d)
+			_ = logger.Log(l.LevelInfo, "user", "b", "user", "c")
+			assert.Equal(t, append([]interface{}{l.LevelInfo}, want...), rec.entries[0])
+		})
+	}
+
+	rec := &recorder{}
+	logger := l.With(rec, "user", "a")
+	l.SetDuplicates(logger, l.DupSuffix)
+	_ = logger.Log(l.LevelInfo, "user", "b", "user_1", "c")
+	assert.Equal(t, []interface{}{l.LevelInfo, "user", "a", "user_2", "b", "user_1", "c"}, rec.entries[0])
+}
+
+func TestWithOverride(t *testing.T) {
+	rec := &recorder{}
+	parent := l.Named(l.With(rec, "user", "a", "id", 1), "api")
+	child := l.WithOverride(parent, l.String("user", "b"), "role", "admin")
+	_ = child.Log(l.LevelInfo, "msg", "m")
+	_ = parent.Log(l.LevelInfo, "msg", "m")
+	assert.Equal(t, []interface{}{l.LevelInfo, "id", 1, "user", l.String("user", "b"), "role", "admin", l.NameKey, "api", "msg", "m"}, rec.entries[0])
+	assert.Equal(t, []interface{}{l.LevelInfo, "user", "a", "id", 1, l.NameKey, "api", "msg", "m"}, rec.entries[1])
+}
@@ -1,6 +1,9 @@
 package log
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 // Level is a logger level.
 type Level int8
@@ -9,20 +12,27 @@ type Level int8
 const LevelKey = "level"
 
 const (
+	// LevelTrace is logger trace level.
+	LevelTrace Level = iota - 2
 	// LevelDebug is logger debug level.
-	LevelDebug Level = iota - 1
+	LevelDebug
 	// LevelInfo is logger info level.
 	LevelInfo
 	// LevelWarn is logger warn level.
 	LevelWarn
 	// LevelError is logger error level.
 	LevelError
-	// LevelFatal is logger fatal level
+	// LevelPanic is logger panic level. Backends only write the entry, the
+	// Panic methods of Log panic once it is written.
+	LevelPanic
+	// LevelFatal is logger fatal level.
 	LevelFatal
 )
 
 func (l Level) String() string {
 	switch l {
+	case LevelTrace:
+		return "TRACE"
 	case LevelDebug:
 		return "DEBUG"
 	case LevelInfo:
@@ -31,6 +41,8 @@ func (l Level) String() string {
 		return "WARN"
 	case LevelError:
 		return "ERROR"
+	case LevelPanic:
+		return "PANIC"
 	case LevelFatal:
 		return "FATAL"
 	default:
@@ -38,19 +50,59 @@ func (l Level) String() string {
 	}
 }
 
-// ParseLevel parses a level string into a logger Level value.
+// ParseLevel parses a level string into a logger Level value, unknown
+// strings are LevelInfo.
 func ParseLevel(s string) Level {
+	level, err := ParseLevelE(s)
+	if err != nil {
+		return LevelInfo
+	}
+	return level
+}
+
+// ParseLevelE parses a level string, case insensitive, into a logger Level
+// value and returns an error for unknown strings.
+func ParseLevelE(s string) (Level, error) {
 	switch strings.ToUpper(s) {
+	case "TRACE":
+		return LevelTrace, nil
 	case "DEBUG":
-		return LevelDebug
+		return LevelDebug, nil
 	case "INFO":
-		return LevelInfo
+		return LevelInfo, nil
 	case "WARN":
-		return LevelWarn
+		return LevelWarn, nil
 	case "ERROR":
-		return LevelError
+		return LevelError, nil
+	case "PANIC":
+		return LevelPanic, nil
 	case "FATAL":
-		return LevelFatal
+		return LevelFatal, nil
+	}
+	return LevelInfo, fmt.Errorf("log: unrecognized level %q", s)
+}
+
+// MarshalText implements encoding.TextMarshaler, levels are written lower case.
+func (l Level) MarshalText() ([]byte, error) {
+	s := l.String()
+	if s == "" {
+		return nil, fmt.Errorf("log: unrecognized level %d", l)
 	}
-	return LevelInfo
+	return []byte(strings.ToLower(s)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so a Level can be
+// decoded from JSON, YAML or TOML.
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevelE(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// Set implements flag.Value, e.g. flag.Var(&level, "level", "log level").
+func (l *Level) Set(s string) error {
+	return l.UnmarshalText([]byte(s))
 }
@@ -1,6 +1,10 @@
 package log
 
-import "testing"
+import (
+	"encoding/json"
+	"flag"
+	"testing"
+)
 
 func TestLevel_String(t *testing.T) {
 	tests := []struct {
@@ -8,6 +12,11 @@ func TestLevel_String(t *testing.T) {
 		l    Level
 		want string
 	}{
+		{
+			name: "TRACE",
+			l:    LevelTrace,
+			want: "TRACE",
+		},
 		{
 			name: "DEBUG",
 			l:    LevelDebug,
@@ -28,6 +37,11 @@ func TestLevel_String(t *testing.T) {
 			l:    LevelError,
 			want: "ERROR",
 		},
+		{
+			name: "PANIC",
+			l:    LevelPanic,
+			want: "PANIC",
+		},
 		{
 			name: "FATAL",
 			l:    LevelFatal,
@@ -54,6 +68,11 @@ func TestParseLevel(t *testing.T) {
 		s    string
 		want Level
 	}{
+		{
+			name: "TRACE",
+			want: LevelTrace,
+			s:    "trace",
+		},
 		{
 			name: "DEBUG",
 			want: LevelDebug,
@@ -74,6 +93,11 @@ func TestParseLevel(t *testing.T) {
 			want: LevelError,
 			s:    "ERROR",
 		},
+		{
+			name: "PANIC",
+			want: LevelPanic,
+			s:    "PANIC",
+		},
 		{
 			name: "FATAL",
 			want: LevelFatal,
@@ -93,3 +117,39 @@ func TestParseLevel(t *testing.T) {
 		})
 	}
 }
+
+func TestParseLevelE(t *testing.T) {
+	level, err := ParseLevelE("Warn")
+	if err != nil || level != LevelWarn {
+		t.Errorf("ParseLevelE() = %v, %v, want %v", level, err, LevelWarn)
+	}
+	if _, err = ParseLevelE("loud"); err == nil {
+		t.Error("ParseLevelE() want error")
+	}
+}
+
+func TestLevel_Text(t *testing.T) {
+	var v struct {
+		Level Level `json:"level"`
+	}
+	if err := json.Unmarshal([]byte(`{"level":"ERROR"}`), &v); err != nil || v.Level != LevelError {
+		t.Errorf("Unmarshal() = %v, %v", v.Level, err)
+	}
+	b, err := json.Marshal(v)
+	if err != nil || string(b) != `{"level":"error"}` {
+		t.Errorf("Marshal() = %s, %v", b, err)
+	}
+	if err = json.Unmarshal([]byte(`{"level":"loud"}`), &v); err == nil {
+		t.Error("Unmarshal() want error")
+	}
+	if _, err = Level(10).MarshalText(); err == nil {
+		t.Error("MarshalText() want error")
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	level := LevelInfo
+	fs.Var(&level, "level", "log level")
+	if err = fs.Parse([]string{"-level", "trace"}); err != nil || level != LevelTrace {
+		t.Errorf("Parse() = %v, %v", level, err)
+	}
+}
@@ -1,5 +1,10 @@
 package log
 
+import (
+	"context"
+	"time"
+)
+
 type BaseLog interface {
 	Logger
 	Info(a ...interface{})
@@ -8,6 +13,13 @@ type BaseLog interface {
 }
 type Log interface {
 	BaseLog
+	Named(name string) Log
+	WithContext(ctx context.Context) Log
+	SetLevelFor(level string, d time.Duration)
+	Trace(a ...interface{})
+	Tracef(format string, a ...interface{})
+	Tracew(keyvals ...interface{})
+
 	Debug(a ...interface{})
 	Debugf(format string, a ...interface{})
 	Debugw(keyvals ...interface{})
@@ -21,6 +33,26 @@ type Log interface {
 	Errorf(format string, a ...interface{})
 	Errorw(keyvals ...interface{})
 
+	TraceContext(ctx context.Context, a ...interface{})
+	DebugContext(ctx context.Context, a ...interface{})
+	InfoContext(ctx context.Context, a ...interface{})
+	WarnContext(ctx context.Context, a ...interface{})
+	ErrorContext(ctx context.Context, a ...interface{})
+	TracefContext(ctx context.Context, format string, a ...interface{})
+	TracewContext(ctx context.Context, keyvals ...interface{})
+	DebugfContext(ctx context.Context, format string, a ...interface{})
+	DebugwContext(ctx context.Context, keyvals ...interface{})
+	InfofContext(ctx context.Context, format string, a ...interface{})
+	InfowContext(ctx context.Context, keyvals ...interface{})
+	WarnfContext(ctx context.Context, format string, a ...interface{})
+	WarnwContext(ctx context.Context, keyvals ...interface{})
+	ErrorfContext(ctx context.Context, format string, a ...interface{})
+	ErrorwContext(ctx context.Context, keyvals ...interface{})
+
+	Panic(a ...interface{})
+	Panicf(format string, a ...interface{})
+	Panicw(keyvals ...interface{})
+
 	Fatal(a ...interface{})
 	Fatalf(format string, a ...interface{})
 	Fatalw(keyvals ...interface{})
@@ -1,8 +1,10 @@
 package log
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"time"
 
 	log2 "github.com/ysk229/go-logs"
 	"github.com/ysk229/go-logs/config"
@@ -16,13 +18,20 @@ var (
 	DefaultMessageKey = "msg"
 
 	// DefaultLogger is default logger.
-	DefaultLogger          = New(&config.Config{})
-	_             log2.Log = (*l)(nil)
+	DefaultLogger                       = New(&config.Config{})
+	_             log2.Log              = (*l)(nil)
+	_             log2.NamedLeveler     = (*l)(nil)
+	_             log2.TemporaryLeveler = (*l)(nil)
 )
 
 type l struct {
-	log    log2.Logger
-	msgKey string
+	log          log2.Logger
+	msgKey       string
+	filters      []log2.FilterOption
+	dedup        []log2.DedupOption
+	stats        *log2.SampleStats
+	callerSkip   int
+	callerFormat log2.CallerFormat
 }
 
 // Option is WrapLogger option.
@@ -35,58 +44,295 @@ func WithMessageKey(k string) Option {
 	}
 }
 
+// AddCallerSkip reports the caller n frames further up, for the loggers
+// wrapped in helpers that are not registered with log.RegisterHelper. It adds
+// up when given more than once.
+func AddCallerSkip(n int) Option {
+	return func(o *l) {
+		o.callerSkip += n
+	}
+}
+
+// WithCallerFormat sets how the caller is printed, log.CallerShort by default.
+func WithCallerFormat(f log2.CallerFormat) Option {
+	return func(o *l) {
+		o.callerFormat = f
+	}
+}
+
+// WithFilter drops and masks entries with a log.Filter before they reach the backend.
+func WithFilter(opts ...log2.FilterOption) Option {
+	return func(o *l) {
+		o.filters = append(o.filters, opts...)
+	}
+}
+
+// WithDedup suppresses repeated entries with a log.Dedup, the "ts" field is
+// left out when comparing them.
+func WithDedup(opts ...log2.DedupOption) Option {
+	return func(o *l) {
+		o.dedup = append(append(o.dedup, log2.DedupIgnore("ts")), opts...)
+	}
+}
+
 func New(conf *config.Config, opts ...Option) log2.Log {
+	optLog := newLog(opts)
+	if conf != nil && conf.Sample.Enabled() {
+		optLog.stats = &log2.SampleStats{}
+	}
+	backend, logType := newBackend(conf)
+	optLog.log = withType(optLog.wrap(optLog.withConf(backend, conf)), conf, logType)
+	if conf != nil {
+		setLevels(optLog.log, conf, nil)
+		setPolicy(optLog.log, conf)
+	}
+	return optLog
+}
+
+func newLog(opts []Option) *l {
+	optLog := &l{msgKey: DefaultMessageKey}
+	for _, o := range opts {
+		o(optLog)
+	}
+	return optLog
+}
+
+// wrap adds the dedup, the filter and the caller of the methods of l on top
+// of backend.
+func (l *l) wrap(backend log2.Logger) log2.Logger {
+	if len(l.dedup) > 0 {
+		backend = log2.NewDedup(backend, l.dedup...)
+	}
+	if len(l.filters) > 0 {
+		backend = log2.NewFilter(backend, append([]log2.FilterOption{log2.FilterMessageKey(l.msgKey)}, l.filters...)...)
+	}
+	return log2.With(backend, "caller", log2.CallerResolver(log2.CallerSkip(l.callerSkip), log2.CallerWithFormat(l.callerFormat)))
+}
+
+// NewE is like New but validates conf first and returns every invalid field
+// instead of falling back to defaults.
+func NewE(conf *config.Config, opts ...Option) (log2.Log, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+	return New(conf, opts...), nil
+}
+
+// newBackend builds the logger selected by conf.Type and returns it with its type name.
+func newBackend(conf *config.Config) (log2.Logger, string) {
 	logType := ""
 	if conf != nil {
 		logType = conf.Type // "zap" //std zap logrus
 	}
-	var logger log2.Logger
 	switch logType {
 	case "logrus":
-		l := logrus.New(conf)
-		logger = log2.With(l, "caller", log2.Caller(4), "type", "logrus")
+		return logrus.New(conf), logType
 	case "std":
-		l := std.NewStdLogger(log.Writer())
-		logger = log2.With(l, "ts", log2.DefaultTimestamp,
-			"caller", log2.Caller(4), "type", "std")
+		return std.NewStdLogger(log.Writer()), logType
 	default:
-		l := zap.New(conf)
+		return zap.New(conf), "zap"
+	}
+}
 
-		logger = log2.With(l, "caller", log2.Caller(4), "type", "zap")
+// withType adds the fields that depend on the backend type, the timestamp of
+// std as conf sets it.
+func withType(logger log2.Logger, conf *config.Config, logType string) log2.Logger {
+	if logType == "std" {
+		return log2.With(logger, "ts", conf.TimeFormat().Valuer(), "type", logType)
 	}
-	optLog := &l{log: logger, msgKey: DefaultMessageKey}
-	for _, o := range opts {
-		o(optLog)
+	return log2.With(logger, "type", logType)
+}
+
+// withConf adds the stack traces, the sampling and the PII scrubbing
+// configured in conf, sampling counts into l.stats.
+func (l *l) withConf(logger log2.Logger, conf *config.Config) log2.Logger {
+	logger = withStack(logger, conf)
+	if conf == nil {
+		return logger
 	}
-	return optLog
+	if len(conf.PII.Detectors) > 0 {
+		redaction := log2.Redaction(conf.PII.Redaction)
+		if redaction == "" {
+			redaction = log2.RedactMask
+		}
+		logger = log2.NewFilter(logger, log2.FilterMessageKey(l.msgKey), log2.FilterPII(redaction, conf.PII.Salt, conf.PII.Detectors...))
+	}
+	if conf.Sample.Enabled() {
+		tick, _ := time.ParseDuration(conf.Sample.Tick)
+		opts := []log2.SamplerOption{log2.SampleTick(tick), log2.SampleStatsTo(l.stats), log2.SampleMessageKey(l.msgKey)}
+		for level, rate := range conf.Sample.Levels {
+			opts = append(opts, log2.SampleLevel(log2.ParseLevel(level), rate.First, rate.Thereafter))
+		}
+		if conf.Sample.First > 0 {
+			opts = append(opts, log2.SampleAll(conf.Sample.First, conf.Sample.Thereafter))
+		}
+		logger = log2.NewSampler(logger, opts...)
+	}
+	return logger
+}
+
+// withStack adds the stack traces configured in conf, to the entries at
+// error and above by default.
+func withStack(logger log2.Logger, conf *config.Config) log2.Logger {
+	return conf.StackTracer(logger)
+}
+
+// SampleStats returns the counters of the sampling configured for a Log
+// built by New, nil when it is not sampled.
+func SampleStats(lg log2.Log) *log2.SampleStats {
+	if lg, ok := lg.(*l); ok {
+		return lg.stats
+	}
+	return nil
+}
+
+// setLevels applies conf.Level and conf.Levels, the per name levels, to
+// logger and returns the names it set. Names in reset that are no longer
+// configured go back to the default level.
+func setLevels(logger log2.Logger, conf *config.Config, reset []string) []string {
+	levels, err := conf.NameLevels()
+	if err != nil {
+		return reset
+	}
+	root := string(conf.Level)
+	if root == "" {
+		root = "warn"
+	}
+	if level, ok := levels[""]; ok {
+		root = level
+	}
+	logger.SetLevel(root)
+	for _, name := range reset {
+		if _, ok := levels[name]; !ok {
+			log2.Named(logger, name).SetLevel(root)
+		}
+	}
+	names := make([]string, 0, len(levels))
+	for name, level := range levels {
+		if name != "" {
+			log2.Named(logger, name).SetLevel(level)
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// setPolicy applies the handling of malformed and duplicate keys of conf to logger.
+func setPolicy(logger log2.Logger, conf *config.Config) {
+	log2.SetStrict(logger, conf.StrictKeyvals)
+	duplicates := log2.Duplicates(conf.DuplicateKeys)
+	if duplicates == "" {
+		duplicates = log2.DupKeepAll
+	}
+	log2.SetDuplicates(logger, duplicates)
 }
 
 func (l *l) SetLevel(level string) {
 	l.log.SetLevel(level)
 }
 
+// Enabled reports whether an entry at level would be logged.
+func (l *l) Enabled(level log2.Level) bool {
+	return l.log.Enabled(level)
+}
+
+// Levels returns the levels set so far by logger name, "" is the default level.
+func (l *l) Levels() map[string]log2.Level {
+	if nl, ok := l.log.(log2.NamedLeveler); ok {
+		return nl.Levels()
+	}
+	return nil
+}
+
+// SetNamedLevel sets the level of the logger called name and its children.
+func (l *l) SetNamedLevel(name string, level string) {
+	if nl, ok := l.log.(log2.NamedLeveler); ok {
+		nl.SetNamedLevel(name, level)
+		return
+	}
+	log2.Named(l.log, name).SetLevel(level)
+}
+
+// SetLevelFor sets level for d, then restores the level in effect before,
+// e.g. to log at debug during an incident without having to switch back.
+func (l *l) SetLevelFor(level string, d time.Duration) {
+	if tl, ok := l.log.(log2.TemporaryLeveler); ok {
+		tl.SetLevelFor(level, d)
+	}
+}
+
+// RevertLevel restores the level replaced by SetLevelFor right away.
+func (l *l) RevertLevel() bool {
+	if tl, ok := l.log.(log2.TemporaryLeveler); ok {
+		return tl.RevertLevel()
+	}
+	return false
+}
+
+// WithContext returns a copy of l bound to ctx, whose Valuers and level
+// (see log.ContextWithLevel) apply to every entry.
+func (l *l) WithContext(ctx context.Context) log2.Log {
+	child := *l
+	child.log = log2.WithContext(ctx, l.log)
+	return &child
+}
+
+// Named returns a child logger whose entries carry logger=<parent>.<name>,
+// its level can be changed on its own with SetLevel.
+func (l *l) Named(name string) log2.Log {
+	child := *l
+	child.log = log2.Named(l.log, name)
+	return &child
+}
+
 func (l *l) Log(level log2.Level, keyvals ...interface{}) error {
 	return l.log.Log(level, keyvals...)
 }
 
 func (l *l) Info(a ...interface{}) {
-	_ = l.log.Log(log2.LevelInfo, l.msgKey, fmt.Sprint(a...))
+	if l.log.Enabled(log2.LevelInfo) {
+		_ = l.log.Log(log2.LevelInfo, l.msgKey, fmt.Sprint(a...))
+	}
 }
 
 func (l *l) Warn(a ...interface{}) {
-	_ = l.log.Log(log2.LevelWarn, l.msgKey, fmt.Sprint(a...))
+	if l.log.Enabled(log2.LevelWarn) {
+		_ = l.log.Log(log2.LevelWarn, l.msgKey, fmt.Sprint(a...))
+	}
 }
 
 func (l *l) Error(a ...interface{}) {
-	_ = l.log.Log(log2.LevelError, l.msgKey, fmt.Sprint(a...))
+	if l.log.Enabled(log2.LevelError) {
+		_ = l.log.Log(log2.LevelError, l.msgKey, fmt.Sprint(a...))
+	}
+}
+
+func (l *l) Trace(a ...interface{}) {
+	if l.log.Enabled(log2.LevelTrace) {
+		_ = l.log.Log(log2.LevelTrace, l.msgKey, fmt.Sprint(a...))
+	}
+}
+
+func (l *l) Tracef(format string, a ...interface{}) {
+	if l.log.Enabled(log2.LevelTrace) {
+		_ = l.log.Log(log2.LevelTrace, l.msgKey, fmt.Sprintf(format, a...))
+	}
+}
+
+func (l *l) Tracew(keyvals ...interface{}) {
+	_ = l.log.Log(log2.LevelTrace, keyvals...)
 }
 
 func (l *l) Debug(a ...interface{}) {
-	_ = l.log.Log(log2.LevelDebug, l.msgKey, fmt.Sprint(a...))
+	if l.log.Enabled(log2.LevelDebug) {
+		_ = l.log.Log(log2.LevelDebug, l.msgKey, fmt.Sprint(a...))
+	}
 }
 
 func (l *l) Debugf(format string, a ...interface{}) {
-	_ = l.log.Log(log2.LevelDebug, l.msgKey, fmt.Sprintf(format, a...))
+	if l.log.Enabled(log2.LevelDebug) {
+		_ = l.log.Log(log2.LevelDebug, l.msgKey, fmt.Sprintf(format, a...))
+	}
 }
 
 func (l *l) Debugw(keyvals ...interface{}) {
@@ -94,7 +340,9 @@ func (l *l) Debugw(keyvals ...interface{}) {
 }
 
 func (l *l) Infof(format string, a ...interface{}) {
-	_ = l.log.Log(log2.LevelInfo, l.msgKey, fmt.Sprintf(format, a...))
+	if l.log.Enabled(log2.LevelInfo) {
+		_ = l.log.Log(log2.LevelInfo, l.msgKey, fmt.Sprintf(format, a...))
+	}
 }
 
 func (l *l) Infow(keyvals ...interface{}) {
@@ -102,7 +350,9 @@ func (l *l) Infow(keyvals ...interface{}) {
 }
 
 func (l *l) Warnf(format string, a ...interface{}) {
-	_ = l.log.Log(log2.LevelWarn, l.msgKey, fmt.Sprintf(format, a...))
+	if l.log.Enabled(log2.LevelWarn) {
+		_ = l.log.Log(log2.LevelWarn, l.msgKey, fmt.Sprintf(format, a...))
+	}
 }
 
 func (l *l) Warnw(keyvals ...interface{}) {
@@ -110,13 +360,136 @@ func (l *l) Warnw(keyvals ...interface{}) {
 }
 
 func (l *l) Errorf(format string, a ...interface{}) {
-	_ = l.log.Log(log2.LevelError, l.msgKey, fmt.Sprintf(format, a...))
+	if l.log.Enabled(log2.LevelError) {
+		_ = l.log.Log(log2.LevelError, l.msgKey, fmt.Sprintf(format, a...))
+	}
 }
 
 func (l *l) Errorw(keyvals ...interface{}) {
 	_ = l.log.Log(log2.LevelError, keyvals...)
 }
 
+// TraceContext is Trace with the Valuers, the level and the context
+// extractors of ctx applied to this entry only.
+func (l *l) TraceContext(ctx context.Context, a ...interface{}) {
+	lg := log2.WithContext(ctx, l.log)
+	if lg.Enabled(log2.LevelTrace) {
+		_ = lg.Log(log2.LevelTrace, l.msgKey, fmt.Sprint(a...))
+	}
+}
+
+func (l *l) DebugContext(ctx context.Context, a ...interface{}) {
+	lg := log2.WithContext(ctx, l.log)
+	if lg.Enabled(log2.LevelDebug) {
+		_ = lg.Log(log2.LevelDebug, l.msgKey, fmt.Sprint(a...))
+	}
+}
+
+func (l *l) InfoContext(ctx context.Context, a ...interface{}) {
+	lg := log2.WithContext(ctx, l.log)
+	if lg.Enabled(log2.LevelInfo) {
+		_ = lg.Log(log2.LevelInfo, l.msgKey, fmt.Sprint(a...))
+	}
+}
+
+func (l *l) WarnContext(ctx context.Context, a ...interface{}) {
+	lg := log2.WithContext(ctx, l.log)
+	if lg.Enabled(log2.LevelWarn) {
+		_ = lg.Log(log2.LevelWarn, l.msgKey, fmt.Sprint(a...))
+	}
+}
+
+func (l *l) ErrorContext(ctx context.Context, a ...interface{}) {
+	lg := log2.WithContext(ctx, l.log)
+	if lg.Enabled(log2.LevelError) {
+		_ = lg.Log(log2.LevelError, l.msgKey, fmt.Sprint(a...))
+	}
+}
+
+// TracefContext is Tracef with ctx applied to this entry only, as TraceContext.
+func (l *l) TracefContext(ctx context.Context, format string, a ...interface{}) {
+	lg := log2.WithContext(ctx, l.log)
+	if lg.Enabled(log2.LevelTrace) {
+		_ = lg.Log(log2.LevelTrace, l.msgKey, fmt.Sprintf(format, a...))
+	}
+}
+
+// TracewContext is Tracew with ctx applied to this entry only, as TraceContext.
+func (l *l) TracewContext(ctx context.Context, keyvals ...interface{}) {
+	_ = log2.WithContext(ctx, l.log).Log(log2.LevelTrace, keyvals...)
+}
+
+func (l *l) DebugfContext(ctx context.Context, format string, a ...interface{}) {
+	lg := log2.WithContext(ctx, l.log)
+	if lg.Enabled(log2.LevelDebug) {
+		_ = lg.Log(log2.LevelDebug, l.msgKey, fmt.Sprintf(format, a...))
+	}
+}
+
+func (l *l) DebugwContext(ctx context.Context, keyvals ...interface{}) {
+	_ = log2.WithContext(ctx, l.log).Log(log2.LevelDebug, keyvals...)
+}
+
+func (l *l) InfofContext(ctx context.Context, format string, a ...interface{}) {
+	lg := log2.WithContext(ctx, l.log)
+	if lg.Enabled(log2.LevelInfo) {
+		_ = lg.Log(log2.LevelInfo, l.msgKey, fmt.Sprintf(format, a...))
+	}
+}
+
+func (l *l) InfowContext(ctx context.Context, keyvals ...interface{}) {
+	_ = log2.WithContext(ctx, l.log).Log(log2.LevelInfo, keyvals...)
+}
+
+func (l *l) WarnfContext(ctx context.Context, format string, a ...interface{}) {
+	lg := log2.WithContext(ctx, l.log)
+	if lg.Enabled(log2.LevelWarn) {
+		_ = lg.Log(log2.LevelWarn, l.msgKey, fmt.Sprintf(format, a...))
+	}
+}
+
+func (l *l) WarnwContext(ctx context.Context, keyvals ...interface{}) {
+	_ = log2.WithContext(ctx, l.log).Log(log2.LevelWarn, keyvals...)
+}
+
+func (l *l) ErrorfContext(ctx context.Context, format string, a ...interface{}) {
+	lg := log2.WithContext(ctx, l.log)
+	if lg.Enabled(log2.LevelError) {
+		_ = lg.Log(log2.LevelError, l.msgKey, fmt.Sprintf(format, a...))
+	}
+}
+
+func (l *l) ErrorwContext(ctx context.Context, keyvals ...interface{}) {
+	_ = log2.WithContext(ctx, l.log).Log(log2.LevelError, keyvals...)
+}
+
+// Panic logs at panic level, then panics with the message once the entry is
+// written, whether or not the level is enabled.
+func (l *l) Panic(a ...interface{}) {
+	l.panic(fmt.Sprint(a...))
+}
+
+func (l *l) Panicf(format string, a ...interface{}) {
+	l.panic(fmt.Sprintf(format, a...))
+}
+
+// Panicw logs keyvals at panic level, then panics with the message or, when
+// there is none, with keyvals.
+func (l *l) Panicw(keyvals ...interface{}) {
+	_ = l.log.Log(log2.LevelPanic, keyvals...)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if keyvals[i] == l.msgKey {
+			panic(fmt.Sprint(keyvals[i+1]))
+		}
+	}
+	panic(fmt.Sprint(keyvals...))
+}
+
+func (l *l) panic(msg string) {
+	_ = l.log.Log(log2.LevelPanic, l.msgKey, msg)
+	panic(msg)
+}
+
 func (l *l) Fatal(a ...interface{}) {
 	_ = l.log.Log(log2.LevelFatal, l.msgKey, fmt.Sprint(a...))
 }
@@ -130,11 +503,15 @@ func (l *l) Fatalw(keyvals ...interface{}) {
 }
 
 func (l *l) Print(v ...interface{}) {
-	_ = l.log.Log(log2.LevelInfo, l.msgKey, fmt.Sprint(v...))
+	if l.log.Enabled(log2.LevelInfo) {
+		_ = l.log.Log(log2.LevelInfo, l.msgKey, fmt.Sprint(v...))
+	}
 }
 
 func (l *l) Println(v ...interface{}) {
-	_ = l.log.Log(log2.LevelInfo, l.msgKey, fmt.Sprint(v...))
+	if l.log.Enabled(log2.LevelInfo) {
+		_ = l.log.Log(log2.LevelInfo, l.msgKey, fmt.Sprint(v...))
+	}
 }
 
 func (l *l) Fatalln(v ...interface{}) {
@@ -1,9 +1,24 @@
 package log
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	log2 "log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
+
+	logs "github.com/ysk229/go-logs"
 	"github.com/ysk229/go-logs/config"
 )
 
@@ -59,3 +74,431 @@ func TestLog(t *testing.T) {
 	l.SetLevel("warn")
 	l.Warnw("key", "value")
 }
+
+func TestNewE(t *testing.T) {
+	l, err := NewE(&config.Config{Type: "std", Level: "info"})
+	if err != nil || l == nil {
+		t.Fatalf("NewE() = %v, %v", l, err)
+	}
+	_, err = NewE(&config.Config{Type: "logrs", Both: "files"})
+	if err == nil {
+		t.Fatal("NewE() expected error")
+	}
+	if !strings.Contains(err.Error(), "type") || !strings.Contains(err.Error(), "both") {
+		t.Errorf("NewE() error = %v, want type and both listed", err)
+	}
+}
+
+// newFileLogger returns the Log New builds from conf, its sinks replaced by a
+// single JSON file, and a func reading back the entries written so far.
+func newFileLogger(t *testing.T, conf *config.Config, opts ...Option) (logs.Log, func() []map[string]interface{}) {
+	t.Helper()
+	dir := t.TempDir()
+	conf.Sinks = []config.Sink{{Name: "all", Type: "file", Format: "json", File: config.FileConf{Path: dir}}}
+	read := func() []map[string]interface{} {
+		t.Helper()
+		b, err := os.ReadFile(filepath.Join(dir, "all.log"))
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		var entries []map[string]interface{}
+		for _, line := range strings.Split(string(b), "\n") {
+			if line == "" {
+				continue
+			}
+			var e map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &e); err != nil {
+				t.Fatalf("%v: %s", err, line)
+			}
+			entries = append(entries, e)
+		}
+		return entries
+	}
+	return New(conf, opts...), read
+}
+
+// find returns the first of entries with msg as message, nil if none has.
+func find(entries []map[string]interface{}, msg string) map[string]interface{} {
+	for _, e := range entries {
+		if e["msg"] == msg {
+			return e
+		}
+	}
+	return nil
+}
+
+// count returns the number of entries with msg as message.
+func count(entries []map[string]interface{}, msg string) int {
+	n := 0
+	for _, e := range entries {
+		if e["msg"] == msg {
+			n++
+		}
+	}
+	return n
+}
+
+func TestNamedLevels(t *testing.T) {
+	l, read := newFileLogger(t, &config.Config{Level: "warn", Levels: "db=debug,http=error"})
+	db := l.Named("db")
+	db.Debug("db debug")
+	db.Named("pool").Info("pool info")
+	l.Named("http").Warn("http warn")
+	l.Info("root info")
+	l.Warn("root warn")
+	pool := l.Named("cache")
+	pool.SetLevel("info")
+	pool.Info("cache info")
+
+	entries := read()
+	for msg, name := range map[string]interface{}{"db debug": "db", "pool info": "db.pool", "root warn": nil, "cache info": "cache"} {
+		if e := find(entries, msg); e == nil || e["logger"] != name {
+			t.Errorf("entry %q = %v, want logger %v", msg, e, name)
+		}
+	}
+	for _, msg := range []string{"http warn", "root info"} {
+		if e := find(entries, msg); e != nil {
+			t.Errorf("unexpected entry %v", e)
+		}
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	for _, typ := range []string{"zap", "logrus", "std"} {
+		t.Run(typ, func(t *testing.T) {
+			h := logs.LevelHandler(New(&config.Config{Type: typ, Level: "error", Levels: "db=info"}))
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if want := `{"level":"error","levels":{"db":"info"}}`; strings.TrimSpace(w.Body.String()) != want {
+				t.Errorf("GET = %s, want %s", w.Body.String(), want)
+			}
+
+			w = httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"name":"db","level":"debug"}`)))
+			if want := `{"level":"error","levels":{"db":"debug"}}`; strings.TrimSpace(w.Body.String()) != want {
+				t.Errorf("PUT = %s, want %s", w.Body.String(), want)
+			}
+		})
+	}
+}
+
+func TestSetLevelFor(t *testing.T) {
+	l := New(&config.Config{Type: "std", Level: "warn"})
+	l.SetLevelFor("debug", 20*time.Millisecond)
+	if got := l.(logs.NamedLeveler).Levels()[""]; got != logs.LevelDebug {
+		t.Fatalf("level = %v, want DEBUG", got)
+	}
+	assert.Eventually(t, func() bool {
+		return l.(logs.NamedLeveler).Levels()[""] == logs.LevelWarn
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestContextWithLevel(t *testing.T) {
+	for _, typ := range []string{"zap", "logrus"} {
+		t.Run(typ, func(t *testing.T) {
+			l, read := newFileLogger(t, &config.Config{Type: typ, Level: "warn"})
+			ctx := logs.ContextWithLevel(context.Background(), logs.LevelDebug)
+			l.WithContext(ctx).Debug("forced debug")
+			l.Debug("plain debug")
+			l.WithContext(context.Background()).Info("plain info")
+			// the backend stays at warn, so does the standard logger redirected to it
+			log2.Println("std info")
+
+			if entries := read(); len(entries) != 1 || entries[0]["msg"] != "forced debug" {
+				t.Errorf("want the forced debug entry only, got %v", entries)
+			}
+		})
+	}
+}
+
+func TestTraceAndPanic(t *testing.T) {
+	for _, typ := range []string{"zap", "logrus"} {
+		t.Run(typ, func(t *testing.T) {
+			l, read := newFileLogger(t, &config.Config{Type: typ, Level: "trace"})
+			l.Tracew("msg", "traced", "k", "v")
+			assertPanics(t, "boom 1", func() { l.Panicf("boom %d", 1) })
+			l.SetLevel("fatal")
+			assertPanics(t, "quiet", func() { l.Panic("quiet") })
+
+			entries := read()
+			if len(entries) != 2 {
+				t.Fatalf("got %d entries, want 2: %v", len(entries), entries)
+			}
+			if entries[0]["level"] != "trace" || entries[0]["msg"] != "traced" {
+				t.Errorf("want a trace entry, got %v", entries[0])
+			}
+			if entries[1]["level"] != "panic" || entries[1]["msg"] != "boom 1" {
+				t.Errorf("want a panic entry, got %v", entries[1])
+			}
+		})
+	}
+}
+
+func assertPanics(t *testing.T, want string, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != want {
+			t.Errorf("recover() = %v, want %v", r, want)
+		}
+	}()
+	f()
+}
+
+func TestWithFilter(t *testing.T) {
+	for _, typ := range []string{"zap", "logrus"} {
+		t.Run(typ, func(t *testing.T) {
+			l, read := newFileLogger(t, &config.Config{Type: typ, Level: "info"},
+				WithFilter(logs.FilterKey("password"), logs.FilterLevel(logs.LevelWarn)))
+			l.Warnw("msg", "login", "password", "secret")
+			l.Info("info dropped")
+
+			entries := read()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1: %v", len(entries), entries)
+			}
+			caller, _ := entries[0]["caller"].(string)
+			if entries[0]["password"] != "***" || !strings.HasPrefix(caller, "log/log_test.go") {
+				t.Errorf("missing masked password or caller in %v", entries[0])
+			}
+		})
+	}
+}
+
+func TestPII(t *testing.T) {
+	l, read := newFileLogger(t, &config.Config{
+		Level: "info",
+		PII:   config.PIIConf{Detectors: []string{"email"}},
+	})
+	l.Infow("msg", "signup a@example.com", "user", "a@example.com")
+
+	if e := find(read(), "signup ***"); e == nil || e["user"] != "***" {
+		t.Errorf("missing masked email in %v", e)
+	}
+}
+
+func TestSample(t *testing.T) {
+	l, read := newFileLogger(t, &config.Config{
+		Level:  "info",
+		Sample: config.SampleConf{Tick: "1h", Levels: map[string]config.Rate{"info": {First: 2}}},
+	})
+	for i := 0; i < 5; i++ {
+		l.Info("hot loop")
+		l.Warn("not sampled")
+	}
+	stats := SampleStats(l)
+	if stats == nil || stats.Sampled() != 2 || stats.Dropped() != 3 {
+		t.Fatalf("SampleStats() = %+v", stats)
+	}
+	entries := read()
+	if n := count(entries, "hot loop"); n != 2 {
+		t.Errorf("got %d hot loop entries, want 2", n)
+	}
+	if n := count(entries, "not sampled"); n != 5 {
+		t.Errorf("got %d not sampled entries, want 5", n)
+	}
+	if SampleStats(New(&config.Config{Type: "std"})) != nil {
+		t.Error("SampleStats() want nil")
+	}
+}
+
+func TestMessageKeyOption(t *testing.T) {
+	l, read := newFileLogger(t, &config.Config{
+		Level:  "info",
+		Sample: config.SampleConf{Tick: "1h", Levels: map[string]config.Rate{"info": {First: 1}}},
+		PII:    config.PIIConf{Detectors: []string{"email"}, Redaction: string(logs.RedactDrop)},
+	}, WithMessageKey("message"))
+	// sampled per message, and the message is scrubbed instead of dropped
+	l.Info("first")
+	l.Info("second")
+	l.Info("signup a@example.com")
+
+	var got []interface{}
+	for _, e := range read() {
+		got = append(got, e["message"])
+	}
+	if want := []interface{}{"first", "second", "signup "}; !reflect.DeepEqual(got, want) {
+		t.Errorf("messages = %v, want %v", got, want)
+	}
+}
+
+func TestWithDedup(t *testing.T) {
+	l, read := newFileLogger(t, &config.Config{Level: "info"}, WithDedup(logs.DedupWindow(20*time.Millisecond)))
+	for i := 0; i < 3; i++ {
+		l.Error("db down")
+	}
+	assert.Eventually(t, func() bool { return len(read()) == 2 }, time.Second, 5*time.Millisecond)
+	if entries := read(); count(entries, "db down") != 2 || entries[1]["repeated"] != float64(2) {
+		t.Errorf("want one entry and one summary in %v", entries)
+	}
+}
+
+type tenantKey struct{}
+
+func TestInfoContext(t *testing.T) {
+	t.Cleanup(logs.RegisterContextExtractor(func(ctx context.Context) []interface{} {
+		if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+			return []interface{}{"tenant", tenant}
+		}
+		return nil
+	}))
+	l, read := newFileLogger(t, &config.Config{Level: "info"})
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	l.InfoContext(ctx, "per call")
+	l.Info("without context")
+	l.DebugContext(logs.ContextWithLevel(ctx, logs.LevelDebug), "debug per call")
+	l.InfofContext(ctx, "per call %d", 2)
+	l.WarnwContext(ctx, "msg", "per call w", "n", 3)
+	l.DebugfContext(ctx, "debug %s", "dropped")
+	l.DebugwContext(logs.ContextWithLevel(ctx, logs.LevelDebug), "msg", "debug per call w")
+
+	entries := read()
+	if len(entries) != 6 {
+		t.Fatalf("got %d entries, want 6: %v", len(entries), entries)
+	}
+	for i, msg := range []string{"per call", "without context", "debug per call", "per call 2", "per call w", "debug per call w"} {
+		if entries[i]["msg"] != msg {
+			t.Errorf("entry %d = %v, want message %s", i, entries[i], msg)
+		}
+	}
+	for i, want := range []bool{true, false, true, true, true, true} {
+		if got := entries[i]["tenant"] == "acme"; got != want {
+			t.Errorf("entry %d has tenant %v, want %v: %v", i, got, want, entries[i])
+		}
+		if caller, _ := entries[i]["caller"].(string); !strings.HasPrefix(caller, "log/log_test.go") {
+			t.Errorf("entry %d has the wrong caller: %v", i, entries[i])
+		}
+	}
+}
+
+func TestStrictKeyvals(t *testing.T) {
+	for _, typ := range []string{"zap", "logrus"} {
+		t.Run(typ, func(t *testing.T) {
+			l, read := newFileLogger(t, &config.Config{Type: typ, Level: "info", StrictKeyvals: true})
+			l.Infow("msg", "unpaired", "user")
+			if err := l.Log(logs.LevelInfo, 42, "msg", "non string key"); !errors.Is(err, logs.ErrBadKeyvals) {
+				t.Errorf("Log() error = %v, want %v", err, logs.ErrBadKeyvals)
+			}
+
+			entries := read()
+			if e := find(entries, "unpaired"); e == nil || e[logs.BadKey] != "user" {
+				t.Errorf("missing %s=user in %v", logs.BadKey, e)
+			}
+			if e := find(entries, "non string key"); e == nil || e[logs.BadKey] != float64(42) {
+				t.Errorf("missing %s=42 in %v", logs.BadKey, e)
+			}
+		})
+	}
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	l, read := newFileLogger(t, &config.Config{Level: "info", DuplicateKeys: "suffix"})
+	_ = logs.With(l, "user", "a").Log(logs.LevelInfo, "msg", "dup", "user", "b")
+
+	if e := find(read(), "dup"); e == nil || e["user"] != "a" || e["user_1"] != "b" {
+		t.Errorf("missing suffixed duplicate in %v", e)
+	}
+}
+
+func TestStack(t *testing.T) {
+	for _, typ := range []string{"zap", "logrus"} {
+		t.Run(typ, func(t *testing.T) {
+			dir := t.TempDir()
+			l := New(&config.Config{
+				Type:  typ,
+				Level: "info",
+				Sinks: []config.Sink{
+					{Name: "json", Type: "file", Format: "json", File: config.FileConf{Path: dir}},
+					{Name: "text", Type: "file", Format: "text", File: config.FileConf{Path: dir}},
+				},
+				Stack: config.StackConf{Level: "warn"},
+			})
+			l.Infow("msg", "no stack")
+			l.Warnw("msg", "stack")
+
+			read := func(name string) string {
+				b, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return string(b)
+			}
+			// the frames of go-logs are hidden, the caller comes first
+			lines := strings.Split(read("json.log"), "\n")
+			if strings.Contains(lines[0], `"stack"`) || !strings.Contains(lines[1], `"stack":"github.com/ysk229/go-logs/log.TestStack.func1\n\t`) {
+				t.Errorf("unexpected stack in %s", lines)
+			}
+			if text := read("text.log"); !strings.Contains(text, "\n    stack:\n        github.com/ysk229/go-logs/log.TestStack.func1\n        \t") {
+				t.Errorf("missing stack block in %s", text)
+			}
+		})
+	}
+}
+
+func TestCallerOptions(t *testing.T) {
+	skip, readSkip := newFileLogger(t, &config.Config{Level: "info"}, AddCallerSkip(1))
+	function, readFunction := newFileLogger(t, &config.Config{Level: "info"}, WithCallerFormat(logs.CallerFunction))
+	warn := func(l logs.Log, msg string) {
+		l.Warnw("msg", msg) // a wrapper the caller skips
+	}
+	warn(skip, "skipped")
+	_, _, line, _ := runtime.Caller(0)
+	_ = logs.With(function, "k", "v").Log(logs.LevelWarn, "msg", "function")
+
+	if e := find(readSkip(), "skipped"); e == nil || e["caller"] != "log/log_test.go:"+strconv.Itoa(line-1) {
+		t.Errorf("wrong caller in %v", e)
+	}
+	if e := find(readFunction(), "function"); e == nil || e["caller"] != "log.TestCallerOptions" {
+		t.Errorf("wrong caller in %v", e)
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 600000000, time.UTC)
+	clock := logs.ClockFunc(func() time.Time { return at })
+	for _, typ := range []string{"zap", "logrus"} {
+		t.Run(typ, func(t *testing.T) {
+			epoch, readEpoch := newFileLogger(t, &config.Config{Type: typ, Level: "info", Timestamp: config.TimestampConf{Format: "unix_ms", Clock: clock}})
+			epoch.Infow("msg", "epoch")
+			layout, readLayout := newFileLogger(t, &config.Config{Type: typ, Level: "info", Timestamp: config.TimestampConf{Format: time.RFC1123Z, TimeZone: "Asia/Tokyo", Clock: clock}})
+			layout.Infow("msg", "layout")
+
+			if e := find(readEpoch(), "epoch"); e == nil || e["ts"] != float64(1704164645600) {
+				t.Errorf("want ts 1704164645600 in %v", e)
+			}
+			if e := find(readLayout(), "layout"); e == nil || e["ts"] != "Tue, 02 Jan 2024 12:04:05 +0900" {
+				t.Errorf("want ts Tue, 02 Jan 2024 12:04:05 +0900 in %v", e)
+			}
+		})
+	}
+}
+
+type countingStringer struct{ n *int }
+
+func (s countingStringer) String() string {
+	*s.n++
+	return "formatted"
+}
+
+func TestEnabled(t *testing.T) {
+	for _, typ := range []string{"zap", "logrus", "std"} {
+		t.Run(typ, func(t *testing.T) {
+			l, _ := newFileLogger(t, &config.Config{Type: typ, Level: "warn"})
+			if l.Enabled(logs.LevelInfo) || !l.Enabled(logs.LevelWarn) {
+				t.Errorf("Enabled() does not follow the warn level")
+			}
+			n := 0
+			l.Info(countingStringer{&n})
+			l.Debugf("%s", countingStringer{&n})
+			l.InfoContext(context.Background(), countingStringer{&n})
+			if n != 0 {
+				t.Errorf("disabled entries were formatted %d times", n)
+			}
+			l.InfoContext(logs.ContextWithLevel(context.Background(), logs.LevelInfo), countingStringer{&n})
+			l.Warn(countingStringer{&n})
+			if n != 2 {
+				t.Errorf("enabled entries were formatted %d times, want 2", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	log2 "github.com/ysk229/go-logs"
+	"github.com/ysk229/go-logs/config"
+)
+
+// DefaultReloadInterval is how often a Reloadable polls its config file.
+var DefaultReloadInterval = 2 * time.Second
+
+// ReloadOption is Reloadable option.
+type ReloadOption func(*Reloadable)
+
+// ReloadInterval sets the polling interval of the config file.
+func ReloadInterval(d time.Duration) ReloadOption {
+	return func(r *Reloadable) {
+		if d > 0 {
+			r.interval = d
+		}
+	}
+}
+
+// ReloadEnvPrefix applies environment overrides (see config.LoadWithEnv) on every load.
+func ReloadEnvPrefix(prefix string) ReloadOption {
+	return func(r *Reloadable) {
+		r.env = true
+		r.envPrefix = prefix
+	}
+}
+
+// ReloadErrorHandler is called when the changed file cannot be loaded or is
+// invalid, the previous configuration stays active. By default the error is logged.
+func ReloadErrorHandler(f func(error)) ReloadOption {
+	return func(r *Reloadable) {
+		r.onError = f
+	}
+}
+
+// ReloadLogOptions sets the options applied to the Log.
+func ReloadLogOptions(opts ...Option) ReloadOption {
+	return func(r *Reloadable) {
+		r.opts = opts
+	}
+}
+
+// Reloadable is a Log that rebuilds its backend whenever the watched config
+// file changes, so level, format, outputs and file rotation can be switched
+// without a restart.
+type Reloadable struct {
+	log2.Log
+	sw *swapLogger
+
+	path      string
+	interval  time.Duration
+	env       bool
+	envPrefix string
+	onError   func(error)
+	opts      []Option
+
+	mu      sync.Mutex
+	conf    *config.Config
+	names   []string
+	modTime time.Time
+	size    int64
+
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewReloadable loads the config file at path, builds a Log from it and
+// starts polling the file for changes.
+func NewReloadable(path string, opts ...ReloadOption) (*Reloadable, error) {
+	r := &Reloadable{
+		path:     path,
+		interval: DefaultReloadInterval,
+		done:     make(chan struct{}),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	conf, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	backend, logType := newBackend(conf)
+	// fields, names and levels live above the swapLogger and survive reloads
+	optLog := newLog(r.opts)
+	optLog.stats = &log2.SampleStats{}
+	r.sw = &swapLogger{logger: withType(optLog.withConf(backend, conf), conf, logType), closer: closerOf(backend)}
+	optLog.log = optLog.wrap(r.sw)
+	r.Log = optLog
+	r.conf, r.modTime, r.size = conf, fi.ModTime(), fi.Size()
+	r.names = setLevels(optLog.log, conf, nil)
+	setPolicy(optLog.log, conf)
+	if r.onError == nil {
+		r.onError = func(err error) {
+			r.Errorw(r.msgKey(), "config reload failed", "path", path, "error", err)
+		}
+	}
+
+	r.wg.Add(1)
+	go r.watch()
+	return r, nil
+}
+
+// Config returns the configuration currently in use.
+func (r *Reloadable) Config() *config.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.conf
+}
+
+// Reload loads the config file and swaps the backend. Entries being written
+// finish on the old backend, whose files are closed afterwards.
+func (r *Reloadable) Reload() error {
+	conf, err := r.load()
+	if err != nil {
+		return err
+	}
+	backend, logType := newBackend(conf)
+	err = r.sw.swap(withType(r.Log.(*l).withConf(backend, conf), conf, logType), closerOf(backend))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.conf = conf
+	r.names = setLevels(r.logger(), conf, r.names)
+	setPolicy(r.logger(), conf)
+	return err
+}
+
+// Levels returns the levels set so far by logger name, "" is the default level.
+func (r *Reloadable) Levels() map[string]log2.Level {
+	return r.Log.(*l).Levels()
+}
+
+// SetNamedLevel sets the level of the logger called name and its children.
+func (r *Reloadable) SetNamedLevel(name string, level string) {
+	r.Log.(*l).SetNamedLevel(name, level)
+}
+
+// RevertLevel restores the level replaced by SetLevelFor right away.
+func (r *Reloadable) RevertLevel() bool {
+	return r.Log.(*l).RevertLevel()
+}
+
+// SampleStats returns the sampling counters, kept across reloads.
+func (r *Reloadable) SampleStats() *log2.SampleStats {
+	return r.Log.(*l).stats
+}
+
+// Close stops watching the file and closes the current backend.
+func (r *Reloadable) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.done)
+	})
+	r.wg.Wait()
+	return r.sw.swap(nil, nil)
+}
+
+func (r *Reloadable) load() (conf *config.Config, err error) {
+	if r.env {
+		conf, err = config.LoadWithEnv(r.path, r.envPrefix)
+	} else {
+		conf, err = config.Load(r.path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = conf.Validate(); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+func (r *Reloadable) msgKey() string {
+	return r.Log.(*l).msgKey
+}
+
+func (r *Reloadable) logger() log2.Logger {
+	return r.Log.(*l).log
+}
+
+func (r *Reloadable) watch() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				r.onError(err)
+			}
+		}
+	}
+}
+
+func (r *Reloadable) changed() bool {
+	fi, err := os.Stat(r.path)
+	if err != nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if fi.ModTime().Equal(r.modTime) && fi.Size() == r.size {
+		return false
+	}
+	r.modTime, r.size = fi.ModTime(), fi.Size()
+	return true
+}
+
+// swapLogger forwards to a logger that can be replaced while in use.
+type swapLogger struct {
+	mu     sync.RWMutex
+	logger log2.Logger
+	closer io.Closer
+}
+
+func (s *swapLogger) Log(level log2.Level, keyvals ...interface{}) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.logger == nil {
+		return nil
+	}
+	return s.logger.Log(level, keyvals...)
+}
+
+func (s *swapLogger) ForceLog(level log2.Level, keyvals ...interface{}) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.logger == nil {
+		return nil
+	}
+	return log2.ForceLog(s.logger, level, keyvals...)
+}
+
+func (s *swapLogger) SetLevel(level string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.logger != nil {
+		s.logger.SetLevel(level)
+	}
+}
+
+func (s *swapLogger) Enabled(level log2.Level) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.logger != nil && s.logger.Enabled(level)
+}
+
+// swap waits for in-flight entries, installs logger and closes the old backend.
+func (s *swapLogger) swap(logger log2.Logger, closer io.Closer) error {
+	s.mu.Lock()
+	old := s.closer
+	s.logger, s.closer = logger, closer
+	s.mu.Unlock()
+	if old == nil {
+		return nil
+	}
+	if err := old.Close(); err != nil {
+		return fmt.Errorf("close previous logger: %w", err)
+	}
+	return nil
+}
+
+func closerOf(logger log2.Logger) io.Closer {
+	if c, ok := logger.(io.Closer); ok {
+		return c
+	}
+	return nil
+}
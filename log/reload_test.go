@@ -0,0 +1,77 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConf(t *testing.T, path, format, dir string) {
+	t.Helper()
+	data := fmt.Sprintf("log:\n  type: zap\n  both: file\n  level: info\n  format: %s\n  file:\n    path: %q\n", format, dir)
+	assert.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+}
+
+func TestReloadable(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.yaml")
+	textDir, jsonDir := filepath.Join(dir, "text"), filepath.Join(dir, "json")
+	writeConf(t, path, "text", textDir)
+
+	var errs []error
+	r, err := NewReloadable(path,
+		ReloadInterval(10*time.Millisecond),
+		ReloadErrorHandler(func(err error) { errs = append(errs, err) }),
+	)
+	assert.NoError(t, err)
+	r.Infow("msg", "before reload")
+
+	writeConf(t, path, "json", jsonDir)
+	assert.Eventually(t, func() bool {
+		return r.Config().Format == "json"
+	}, time.Second, 10*time.Millisecond)
+	r.Infow("msg", "after reload")
+	assert.NoError(t, r.Close())
+	assert.Empty(t, errs)
+
+	text, err := os.ReadFile(filepath.Join(textDir, "info.log"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(text), "before reload")
+	assert.NotContains(t, string(text), "after reload")
+
+	js, err := os.ReadFile(filepath.Join(jsonDir, "info.log"))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(js), "{"))
+	assert.Contains(t, string(js), `"msg":"after reload"`)
+}
+
+func TestReloadableError(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.yaml")
+	writeConf(t, path, "text", dir)
+
+	errs := make(chan error, 1)
+	r, err := NewReloadable(path,
+		ReloadInterval(10*time.Millisecond),
+		ReloadErrorHandler(func(err error) { errs <- err }),
+	)
+	assert.NoError(t, err)
+	defer r.Close()
+
+	assert.NoError(t, os.WriteFile(path, []byte("log:\n  level: [\n"), 0o600))
+	select {
+	case err := <-errs:
+		assert.Contains(t, err.Error(), path)
+	case <-time.After(time.Second):
+		t.Fatal("reload error not reported")
+	}
+	assert.Equal(t, "text", r.Config().Format)
+
+	_, err = NewReloadable(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
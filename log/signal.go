@@ -0,0 +1,48 @@
+package log
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	log2 "github.com/ysk229/go-logs"
+)
+
+// ToggleLevelOnSignal switches lg to level for d whenever one of sigs is
+// received, a second signal before d expires switches back right away. sigs
+// defaults to SIGUSR1 where the platform has it. Call stop to stop listening.
+//
+//	stop := log.ToggleLevelOnSignal(l, "debug", 10*time.Minute) // kill -USR1 <pid>
+//	defer stop()
+func ToggleLevelOnSignal(lg log2.Logger, level string, d time.Duration, sigs ...os.Signal) (stop func()) {
+	tl, ok := lg.(log2.TemporaryLeveler)
+	if len(sigs) == 0 {
+		sigs = toggleSignals
+	}
+	if !ok || len(sigs) == 0 {
+		return func() {}
+	}
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ch:
+				if !tl.RevertLevel() {
+					tl.SetLevelFor(level, d)
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package log
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	logs "github.com/ysk229/go-logs"
+	"github.com/ysk229/go-logs/config"
+)
+
+func TestToggleLevelOnSignal(t *testing.T) {
+	l := New(&config.Config{Type: "std", Level: "warn"})
+	stop := ToggleLevelOnSignal(l, "debug", time.Minute)
+	defer stop()
+	level := func() logs.Level {
+		return l.(logs.NamedLeveler).Levels()[""]
+	}
+
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool { return level() == logs.LevelDebug }, time.Second, 5*time.Millisecond)
+
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool { return level() == logs.LevelWarn }, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,9 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package log
+
+import "os"
+
+// toggleSignals is empty, SIGUSR1 does not exist here.
+var toggleSignals []os.Signal
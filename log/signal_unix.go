@@ -0,0 +1,11 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package log
+
+import (
+	"os"
+	"syscall"
+)
+
+var toggleSignals = []os.Signal{syscall.SIGUSR1}
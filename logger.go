@@ -1,11 +1,43 @@
 package log
 
-import "context"
+import (
+	"context"
+	"strings"
+	"time"
+)
 
 // Logger is a logger interface.
 type Logger interface {
 	Log(level Level, keyvals ...interface{}) error
 	SetLevel(level string)
+	// Enabled reports whether an entry at level would be logged, so callers
+	// can skip the work of building it.
+	Enabled(level Level) bool
+}
+
+// ForceLogger is implemented by the Loggers that can log an entry whatever
+// their level. The loggers bound to a context carrying a level (see
+// ContextWithLevel) log through it, so the level of the backend, which may
+// also filter the standard library and global loggers, stays the lowest one
+// set. Wrappers pass it on to the Logger they wrap.
+type ForceLogger interface {
+	ForceLog(level Level, keyvals ...interface{}) error
+}
+
+// ForceLog logs through the ForceLog method of l, or else through Log.
+func ForceLog(l Logger, level Level, keyvals ...interface{}) error {
+	if f, ok := l.(ForceLogger); ok {
+		return f.ForceLog(level, keyvals...)
+	}
+	return l.Log(level, keyvals...)
+}
+
+// logTo logs through ForceLog when force is set, else through Log.
+func logTo(l Logger, force bool, level Level, keyvals []interface{}) error {
+	if force {
+		return ForceLog(l, level, keyvals...)
+	}
+	return l.Log(level, keyvals...)
 }
 
 type logger struct {
@@ -13,30 +45,125 @@ type logger struct {
 	prefix    []interface{}
 	hasValuer bool
 	ctx       context.Context
+	bound     bool // ctx was set by WithContext
+	name      string
+	levels    *nameLevels
+	policy    *policy
+	badPrefix error // the checkKeyvals error of the first malformed keyvals given to With
 }
 
+// Log resolves the Valuers and LogValuers of the entry, only when it is
+// enabled, leaves out the fields they value Omit, replaces its errors with
+// their ErrorDetail and passes it on.
 func (c *logger) Log(level Level, keyvals ...interface{}) error {
-	kvs := make([]interface{}, 0, len(c.prefix)+len(keyvals))
+	if !c.Enabled(level) {
+		return nil
+	}
+	_, force := LevelFromContext(c.ctx)
+	return c.log(level, force, keyvals)
+}
+
+// ForceLog logs the entry whatever the levels of this logger and of the
+// wrapped one.
+func (c *logger) ForceLog(level Level, keyvals ...interface{}) error {
+	return c.log(level, true, keyvals)
+}
+
+func (c *logger) log(level Level, force bool, keyvals []interface{}) error {
+	var ctxFields []interface{}
+	if c.bound {
+		ctxFields = contextFields(c.ctx)
+	}
+	kvs := make([]interface{}, 0, len(c.prefix)+2*len(keyvals)+len(ctxFields)+2) // a Field takes two
 	kvs = append(kvs, c.prefix...)
 	if c.hasValuer {
 		bindValues(c.ctx, kvs)
 	}
-	kvs = append(kvs, keyvals...)
-	if err := c.logger.Log(level, kvs...); err != nil {
+	if c.name != "" {
+		kvs = append(kvs, NameKey, c.name)
+	}
+	n := len(kvs)
+	kvs = appendKeyvals(kvs, keyvals)
+	bindValues(c.ctx, kvs[n:])
+	kvs = omitValues(kvs)
+	kvs = appendKeyvals(kvs, ctxFields)
+	errorValues(kvs)
+	kvs = c.policy.resolve(kvs)
+	if err := logTo(c.logger, force, level, kvs); err != nil {
 		return err
 	}
+	if c.policy.isStrict() {
+		if c.badPrefix != nil {
+			return c.badPrefix
+		}
+		return checkKeyvals(keyvals)
+	}
 	return nil
 }
 
+// Enabled reports whether level passes the level of the bound context, or
+// else the levels of this logger's name and of the wrapped logger.
+func (c *logger) Enabled(level Level) bool {
+	if min, ok := LevelFromContext(c.ctx); ok {
+		return level >= min
+	}
+	return c.levels.enabled(c.name, level) && c.logger.Enabled(level)
+}
+
+// SetLevel sets the level of this logger's name and its children, the
+// unnamed logger sets the default level. The wrapped logger is set to the
+// lowest level in use and filtering happens here, per name and per context
+// (see ContextWithLevel).
 func (c *logger) SetLevel(level string) {
-	c.logger.SetLevel(level)
+	c.SetNamedLevel(c.name, level)
+}
+
+// SetNamedLevel sets the level of the logger called name, see SetLevel.
+func (c *logger) SetNamedLevel(name string, level string) {
+	c.levels.set(name, ParseLevel(level))
+	c.syncLevel()
+}
+
+// SetLevelFor sets the level of this logger's name for d, then restores the
+// level it had before. SetLevel in the meantime cancels the restore.
+func (c *logger) SetLevelFor(level string, d time.Duration) {
+	c.levels.setFor(c.name, ParseLevel(level), d, c.syncLevel)
+	c.syncLevel()
+}
+
+// RevertLevel ends a SetLevelFor early.
+func (c *logger) RevertLevel() bool {
+	if !c.levels.revert(c.name, 0) {
+		return false
+	}
+	c.syncLevel()
+	return true
 }
 
-// With with logger fields.
+// syncLevel sets the wrapped logger to the lowest level set here, so it
+// logs every name without opening up further.
+func (c *logger) syncLevel() {
+	if min, ok := c.levels.min(); ok {
+		c.logger.SetLevel(strings.ToLower(min.String()))
+	}
+}
+
+// Levels returns the levels set on this logger and its relatives by name.
+func (c *logger) Levels() map[string]Level {
+	return c.levels.all()
+}
+
+// With with logger fields, kv is made of pairs as the keyvals of Log are
+// (see BadKey).
 func With(l Logger, kv ...interface{}) Logger {
+	bad := checkKeyvals(kv)
+	kv = appendKeyvals(nil, kv)
 	c, ok := l.(*logger)
 	if !ok {
-		return &logger{logger: l, prefix: kv, hasValuer: containsValuer(kv), ctx: context.Background()}
+		return &logger{logger: l, prefix: kv, hasValuer: containsValuer(kv), ctx: context.Background(), levels: newNameLevels(), policy: &policy{}, badPrefix: bad}
+	}
+	if c.badPrefix != nil {
+		bad = c.badPrefix
 	}
 	kvs := make([]interface{}, 0, len(c.prefix)+len(kv))
 	kvs = append(kvs, c.prefix...)
@@ -46,20 +173,84 @@ func With(l Logger, kv ...interface{}) Logger {
 		prefix:    kvs,
 		hasValuer: containsValuer(kvs),
 		ctx:       c.ctx,
+		bound:     c.bound,
+		name:      c.name,
+		levels:    c.levels,
+		policy:    c.policy,
+		badPrefix: bad,
 	}
 }
 
+// WithOverride is With, except that the fields of l with the keys of kv are
+// dropped, so a child logger can replace the fields it inherits.
+func WithOverride(l Logger, kv ...interface{}) Logger {
+	c, ok := l.(*logger)
+	if !ok {
+		return With(l, kv...)
+	}
+	keys := appendKeyvals(nil, kv)
+	prefix := make([]interface{}, 0, len(c.prefix))
+	for i := 0; i < len(c.prefix); i += 2 {
+		if indexKey(keys, c.prefix[i]) < 0 {
+			prefix = append(prefix, c.prefix[i], c.prefix[i+1])
+		}
+	}
+	return With(&logger{
+		logger:    c.logger,
+		prefix:    prefix,
+		ctx:       c.ctx,
+		bound:     c.bound,
+		name:      c.name,
+		levels:    c.levels,
+		policy:    c.policy,
+		badPrefix: c.badPrefix,
+	}, kv...)
+}
+
 // WithContext returns a shallow copy of l with its context changed
-// to ctx. The provided ctx must be non-nil.
+// to ctx. The provided ctx must be non-nil. The fields of the registered
+// context extractors are appended to every entry.
 func WithContext(ctx context.Context, l Logger) Logger {
 	c, ok := l.(*logger)
 	if !ok {
-		return &logger{logger: l, ctx: ctx}
+		return &logger{logger: l, ctx: ctx, bound: true, levels: newNameLevels(), policy: &policy{}}
 	}
 	return &logger{
 		logger:    c.logger,
 		prefix:    c.prefix,
 		hasValuer: c.hasValuer,
 		ctx:       ctx,
+		bound:     true,
+		name:      c.name,
+		levels:    c.levels,
+		policy:    c.policy,
+		badPrefix: c.badPrefix,
+	}
+}
+
+// Named returns a child of l whose entries carry NameKey with name appended
+// to the parent's name, e.g. Named(Named(l, "db"), "pool") logs logger=db.pool.
+// Its level can be set on its own through SetLevel and applies to its children.
+func Named(l Logger, name string) Logger {
+	c, ok := l.(*logger)
+	if !ok {
+		return &logger{logger: l, ctx: context.Background(), name: name, levels: newNameLevels(), policy: &policy{}}
+	}
+	switch {
+	case name == "":
+		name = c.name
+	case c.name != "":
+		name = c.name + "." + name
+	}
+	return &logger{
+		logger:    c.logger,
+		prefix:    c.prefix,
+		hasValuer: c.hasValuer,
+		ctx:       c.ctx,
+		bound:     c.bound,
+		name:      name,
+		levels:    c.levels,
+		policy:    c.policy,
+		badPrefix: c.badPrefix,
 	}
 }
@@ -3,7 +3,11 @@ package log_test
 import (
 	"context"
 	"log"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 
 	l "github.com/ysk229/go-logs"
 	"github.com/ysk229/go-logs/contrib/std"
@@ -20,3 +24,149 @@ func TestInfo(t *testing.T) {
 func TestWithContext(t *testing.T) {
 	l.WithContext(context.Background(), nil)
 }
+
+type recorder struct {
+	mu      sync.Mutex
+	level   string
+	entries [][]interface{}
+}
+
+func (r *recorder) Log(level l.Level, keyvals ...interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, append([]interface{}{level}, keyvals...))
+	return nil
+}
+
+func (r *recorder) SetLevel(level string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.level = level
+}
+
+func (r *recorder) Enabled(level l.Level) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.level == "" || level >= l.ParseLevel(r.level)
+}
+
+func (r *recorder) getLevel() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.level
+}
+
+func TestNamed(t *testing.T) {
+	rec := &recorder{}
+	root := l.With(rec, "app", "test")
+	db := l.Named(root, "db")
+	pool := l.Named(db, "pool")
+	http := l.Named(root, "http")
+
+	_ = pool.Log(l.LevelInfo, "msg", "connected")
+	assert.Equal(t, []interface{}{l.LevelInfo, "app", "test", "logger", "db.pool", "msg", "connected"}, rec.entries[0])
+
+	// levels are filtered here, the wrapped logger is set to the lowest one
+	root.SetLevel("warn")
+	assert.Equal(t, "warn", rec.getLevel())
+	db.SetLevel("debug")
+	http.SetLevel("error")
+	assert.Equal(t, "debug", rec.getLevel())
+
+	rec.entries = nil
+	_ = root.Log(l.LevelInfo, "msg", "root info")
+	_ = root.Log(l.LevelWarn, "msg", "root warn")
+	_ = pool.Log(l.LevelDebug, "msg", "pool debug")
+	_ = http.Log(l.LevelWarn, "msg", "http warn")
+	_ = l.Named(http, "client").Log(l.LevelError, "msg", "client error")
+	_ = l.With(db, "k", "v").Log(l.LevelDebug, "msg", "db debug")
+
+	msgs := make([]interface{}, 0, len(rec.entries))
+	for _, e := range rec.entries {
+		msgs = append(msgs, e[len(e)-1])
+	}
+	assert.Equal(t, []interface{}{"root warn", "pool debug", "client error", "db debug"}, msgs)
+}
+
+func TestSetLevelFor(t *testing.T) {
+	rec := &recorder{}
+	root := l.With(rec)
+	root.SetLevel("warn")
+	tl := root.(l.TemporaryLeveler)
+	assert.False(t, tl.RevertLevel())
+
+	tl.SetLevelFor("debug", 50*time.Millisecond)
+	assert.Equal(t, "debug", rec.getLevel())
+	_ = root.Log(l.LevelDebug, "msg", "escalated")
+	assert.Len(t, rec.entries, 1)
+	assert.Eventually(t, func() bool {
+		return root.(l.NamedLeveler).Levels()[""] == l.LevelWarn
+	}, time.Second, 5*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return rec.getLevel() == "warn"
+	}, time.Second, 5*time.Millisecond)
+	_ = root.Log(l.LevelDebug, "msg", "reverted")
+	assert.Len(t, rec.entries, 1)
+
+	// a second call extends the period and keeps the original level
+	tl.SetLevelFor("info", time.Hour)
+	tl.SetLevelFor("debug", time.Hour)
+	assert.True(t, tl.RevertLevel())
+	assert.Equal(t, l.LevelWarn, root.(l.NamedLeveler).Levels()[""])
+
+	// SetLevel cancels the restore
+	db := l.Named(root, "db")
+	db.(l.TemporaryLeveler).SetLevelFor("debug", 20*time.Millisecond)
+	db.SetLevel("error")
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, l.LevelError, root.(l.NamedLeveler).Levels()["db"])
+}
+
+func TestContextWithLevel(t *testing.T) {
+	rec := &recorder{}
+	root := l.With(rec)
+	root.SetLevel("warn")
+
+	ctx := l.ContextWithLevel(context.Background(), l.LevelDebug)
+	traced := l.WithContext(ctx, l.Named(root, "db"))
+	_ = traced.Log(l.LevelDebug, "msg", "traced")
+	_ = root.Log(l.LevelDebug, "msg", "untraced")
+	assert.Len(t, rec.entries, 1)
+	assert.Equal(t, "traced", rec.entries[0][len(rec.entries[0])-1])
+	// the context goes around the wrapped logger's level instead of lowering it
+	assert.Equal(t, "warn", rec.getLevel())
+
+	// the context level also holds back entries below it
+	quiet := l.WithContext(l.ContextWithLevel(ctx, l.LevelError), root)
+	_ = quiet.Log(l.LevelWarn, "msg", "quiet")
+	assert.Len(t, rec.entries, 1)
+
+	level, ok := l.LevelFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, l.LevelDebug, level)
+	_, ok = l.LevelFromContext(context.Background())
+	assert.False(t, ok)
+}
+
+type requestIDKey struct{}
+
+func TestContextExtractor(t *testing.T) {
+	unregister := l.RegisterContextExtractor(func(ctx context.Context) []interface{} {
+		if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+			return []interface{}{"request_id", id}
+		}
+		return nil
+	})
+	t.Cleanup(unregister)
+	rec := &recorder{}
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "r1")
+	logger := l.WithContext(ctx, l.With(rec, "app", "test"))
+	_ = l.Named(logger, "db").Log(l.LevelInfo, "msg", "query")
+	_ = l.With(rec, "app", "test").Log(l.LevelInfo, "msg", "unbound")
+	assert.Equal(t, []interface{}{l.LevelInfo, "app", "test", "logger", "db", "msg", "query", "request_id", "r1"}, rec.entries[0])
+	assert.Equal(t, []interface{}{l.LevelInfo, "app", "test", "msg", "unbound"}, rec.entries[1])
+
+	unregister()
+	_ = logger.Log(l.LevelInfo, "msg", "unregistered")
+	assert.Equal(t, []interface{}{l.LevelInfo, "app", "test", "msg", "unregistered"}, rec.entries[2])
+}
@@ -0,0 +1,151 @@
+package log
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// NameKey is logger name key.
+const NameKey = "logger"
+
+// NamedLeveler is implemented by loggers that keep a level per logger name,
+// see Named. The empty name is the default level.
+type NamedLeveler interface {
+	// Levels returns the levels set so far by name.
+	Levels() map[string]Level
+	// SetNamedLevel sets the level of name and its children.
+	SetNamedLevel(name string, level string)
+}
+
+// TemporaryLeveler is implemented by loggers whose level can be changed for
+// a limited time.
+type TemporaryLeveler interface {
+	// SetLevelFor sets level for d, then restores the previous level.
+	SetLevelFor(level string, d time.Duration)
+	// RevertLevel restores the level replaced by SetLevelFor right away, it
+	// reports false when no temporary level is active.
+	RevertLevel() bool
+}
+
+// nameLevels holds the levels set on named loggers. A name without a level
+// of its own uses its closest parent's, "" is the default for every name.
+type nameLevels struct {
+	mu     sync.RWMutex
+	levels map[string]Level
+	temps  map[string]*tempLevel
+}
+
+// tempLevel remembers the level replaced by setFor until it is restored.
+type tempLevel struct {
+	timer *time.Timer
+	gen   int
+	prior Level
+	had   bool
+}
+
+func newNameLevels() *nameLevels {
+	return &nameLevels{levels: make(map[string]Level), temps: make(map[string]*tempLevel)}
+}
+
+// set sets the level of name, a pending temporary level is dropped.
+func (n *nameLevels) set(name string, level Level) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if t, ok := n.temps[name]; ok {
+		t.timer.Stop()
+		delete(n.temps, name)
+	}
+	n.levels[name] = level
+}
+
+// setFor sets the level of name for d and calls done once the previous level
+// is back. Calling it again before d expires extends the period and keeps
+// the original previous level.
+func (n *nameLevels) setFor(name string, level Level, d time.Duration, done func()) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	t, ok := n.temps[name]
+	if ok {
+		t.timer.Stop()
+	} else {
+		t = &tempLevel{}
+		t.prior, t.had = n.levels[name]
+		n.temps[name] = t
+	}
+	t.gen++
+	gen := t.gen
+	n.levels[name] = level
+	t.timer = time.AfterFunc(d, func() {
+		if n.revert(name, gen) {
+			done()
+		}
+	})
+}
+
+// revert restores the level replaced by setFor, gen 0 matches any period.
+func (n *nameLevels) revert(name string, gen int) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	t, ok := n.temps[name]
+	if !ok || (gen != 0 && t.gen != gen) {
+		return false
+	}
+	t.timer.Stop()
+	delete(n.temps, name)
+	if t.had {
+		n.levels[name] = t.prior
+	} else {
+		delete(n.levels, name)
+	}
+	return true
+}
+
+// get returns the level that applies to name, false when none is set.
+func (n *nameLevels) get(name string) (Level, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	for {
+		if level, ok := n.levels[name]; ok {
+			return level, true
+		}
+		if name == "" {
+			return 0, false
+		}
+		if i := strings.LastIndexByte(name, '.'); i >= 0 {
+			name = name[:i]
+		} else {
+			name = ""
+		}
+	}
+}
+
+func (n *nameLevels) enabled(name string, level Level) bool {
+	min, ok := n.get(name)
+	return !ok || level >= min
+}
+
+func (n *nameLevels) all() map[string]Level {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	levels := make(map[string]Level, len(n.levels))
+	for name, level := range n.levels {
+		levels[name] = level
+	}
+	return levels
+}
+
+// min returns the lowest level set, temporary ones included, false when
+// none is.
+func (n *nameLevels) min() (Level, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	var min Level
+	ok := false
+	for _, level := range n.levels {
+		if !ok || level < min {
+			min, ok = level, true
+		}
+	}
+	return min, ok
+}
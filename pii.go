@@ -0,0 +1,188 @@
+package log
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MessageKey is the key of the message of an entry.
+const MessageKey = "msg"
+
+// Detector finds one kind of personal data in strings.
+type Detector struct {
+	Name    string
+	Pattern *regexp.Regexp
+	// Valid confirms a match when set, e.g. the Luhn check of card numbers.
+	Valid func(match string) bool
+}
+
+// Redaction is how a Filter replaces the personal data found by detectors.
+type Redaction string
+
+const (
+	// RedactMask replaces the data with FilterMask.
+	RedactMask Redaction = "mask"
+	// RedactHash replaces the data with a salted sha256 hash, so equal values
+	// can still be correlated.
+	RedactHash Redaction = "hash"
+	// RedactDrop removes the data from the message and drops the fields
+	// holding it.
+	RedactDrop Redaction = "drop"
+)
+
+var detectors = struct {
+	sync.RWMutex
+	names []string // registration order, the order detectors run in
+	m     map[string]*Detector
+}{m: make(map[string]*Detector)}
+
+func init() {
+	RegisterDetector(&Detector{
+		Name:    "jwt",
+		Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	})
+	RegisterDetector(&Detector{
+		Name:    "email",
+		Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	})
+	RegisterDetector(&Detector{
+		Name:    "credit_card",
+		Pattern: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		Valid:   luhn,
+	})
+	RegisterDetector(&Detector{
+		Name: "ipv6",
+		// whole tokens holding a colon, so "std::vector" is not cut into a
+		// "d::" address, less a dot ending the sentence
+		Pattern: regexp.MustCompile(`[\w:.]*:[\w:.]*[\w:]`),
+		Valid: func(match string) bool {
+			return net.ParseIP(match) != nil
+		},
+	})
+	RegisterDetector(&Detector{
+		Name:    "ipv4",
+		Pattern: regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b`),
+	})
+	RegisterDetector(&Detector{
+		Name:    "phone",
+		Pattern: regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{2,4}\)[ .-]?|\b\d{2,3}[ .-])\d{3,4}[ .-]\d{4}\b|\b1[3-9]\d{9}\b`),
+	})
+}
+
+// RegisterDetector adds d to the registry, replacing the detector of the same
+// name. The built-in detectors are jwt, email, credit_card, ipv6, ipv4 and phone.
+func RegisterDetector(d *Detector) {
+	detectors.Lock()
+	defer detectors.Unlock()
+	if _, ok := detectors.m[d.Name]; !ok {
+		detectors.names = append(detectors.names, d.Name)
+	}
+	detectors.m[d.Name] = d
+}
+
+// LookupDetector returns the detector registered as name.
+func LookupDetector(name string) (*Detector, bool) {
+	detectors.RLock()
+	defer detectors.RUnlock()
+	d, ok := detectors.m[name]
+	return d, ok
+}
+
+// DetectorNames returns the names of the registered detectors, sorted.
+func DetectorNames() []string {
+	detectors.RLock()
+	defer detectors.RUnlock()
+	names := append([]string(nil), detectors.names...)
+	sort.Strings(names)
+	return names
+}
+
+// FilterPII scrubs the message and the string field values, or the %v
+// rendering of slices, maps and structs (see FilterPattern), with the named
+// detectors, every registered one when names is empty. salt is used by RedactHash.
+func FilterPII(redaction Redaction, salt string, names ...string) FilterOption {
+	return func(f *Filter) {
+		detectors.RLock()
+		defer detectors.RUnlock()
+		if len(names) == 0 {
+			names = detectors.names
+		}
+		p := &piiScrubber{redaction: redaction, salt: salt}
+		for _, name := range names {
+			if d, ok := detectors.m[name]; ok {
+				p.detectors = append(p.detectors, d)
+			}
+		}
+		f.pii = p
+	}
+}
+
+type piiScrubber struct {
+	detectors []*Detector
+	redaction Redaction
+	salt      string
+}
+
+// scrub returns s with the personal data replaced and whether any was found.
+func (p *piiScrubber) scrub(s string) (string, bool) {
+	found := false
+	for _, d := range p.detectors {
+		matches := d.Pattern.FindAllStringIndex(s, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, m := range matches {
+			match := s[m[0]:m[1]]
+			if d.Valid != nil && !d.Valid(match) {
+				continue
+			}
+			found = true
+			b.WriteString(s[last:m[0]])
+			b.WriteString(p.replace(match))
+			last = m[1]
+		}
+		if last > 0 {
+			b.WriteString(s[last:])
+			s = b.String()
+		}
+	}
+	return s, found
+}
+
+func (p *piiScrubber) replace(match string) string {
+	switch p.redaction {
+	case RedactDrop:
+		return ""
+	case RedactHash:
+		sum := sha256.Sum256([]byte(p.salt + match))
+		return "sha256:" + hex.EncodeToString(sum[:8])
+	}
+	return FilterMask
+}
+
+// luhn reports whether the digits of s pass the Luhn checksum.
+func luhn(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
@@ -0,0 +1,78 @@
+package log_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	l "github.com/ysk229/go-logs"
+)
+
+func TestFilterPII(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"email", "mail a.b+c@example.com now", "mail *** now"},
+		{"credit card", "card 4111 1111 1111 1111 ok", "card *** ok"},
+		{"not luhn", "order 4111 1111 1111 1112", "order 4111 1111 1111 1112"},
+		{"ipv4", "from 192.168.1.10:80", "from ***:80"},
+		{"version", "version 1.2.3", "version 1.2.3"},
+		{"ipv6", "from 2001:db8::1 and fe80::1", "from *** and ***"},
+		{"ipv6 token", "called std::vector::push_back in Foo::Bar at 10:30", "called std::vector::push_back in Foo::Bar at 10:30"},
+		{"ipv6 sentence", "from ::1.", "from ***."},
+		{"time", "at 11:26:10", "at 11:26:10"},
+		{"jwt", "token eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.c2ln end", "token *** end"},
+		{"phone", "call +1 415-555-2671 or 13812345678", "call *** or ***"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			f := l.NewFilter(rec, l.FilterPII(l.RedactMask, ""))
+			_ = f.Log(l.LevelInfo, "msg", tt.in, "field", tt.in)
+			assert.Equal(t, []interface{}{l.LevelInfo, "msg", tt.want, "field", tt.want}, rec.entries[0])
+		})
+	}
+}
+
+func TestFilterPIIRedaction(t *testing.T) {
+	rec := &recorder{}
+	_ = l.NewFilter(rec, l.FilterPII(l.RedactHash, "pepper", "email")).
+		Log(l.LevelInfo, "msg", "a@example.com", "err", errors.New("no a@example.com"), "ip", "10.0.0.1")
+	_ = l.NewFilter(rec, l.FilterPII(l.RedactHash, "salt", "email")).Log(l.LevelInfo, "msg", "a@example.com")
+	hash := rec.entries[0][2].(string)
+	assert.Regexp(t, `^sha256:[0-9a-f]{16}$`, hash)
+	assert.Equal(t, "no "+hash, rec.entries[0][4])
+	assert.Equal(t, "10.0.0.1", rec.entries[0][6], "only the named detectors run")
+	assert.NotEqual(t, hash, rec.entries[1][2], "the salt changes the hash")
+
+	rec.entries = nil
+	_ = l.NewFilter(rec, l.FilterPII(l.RedactDrop, "")).Log(l.LevelInfo, "msg", "user a@example.com", "user", "a@example.com", "id", 1)
+	assert.Equal(t, []interface{}{l.LevelInfo, "msg", "user ", "id", 1}, rec.entries[0])
+}
+
+func TestFilterPIIComposite(t *testing.T) {
+	type user struct{ Name, Email string }
+	rec := &recorder{}
+	_ = l.NewFilter(rec, l.FilterPII(l.RedactMask, "", "email")).Log(l.LevelInfo,
+		"to", []string{"a@example.com", "b"},
+		"by", map[string]string{"a": "a@example.com"},
+		"user", &user{"a", "a@example.com"},
+		"ids", []int{1, 2},
+	)
+	assert.Equal(t, []interface{}{l.LevelInfo, "to", "[*** b]", "by", "map[a:***]", "user", "&{a ***}", "ids", []int{1, 2}}, rec.entries[0])
+}
+
+func TestRegisterDetector(t *testing.T) {
+	l.RegisterDetector(&l.Detector{Name: "test_ssn", Pattern: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)})
+	assert.Contains(t, l.DetectorNames(), "test_ssn")
+	_, ok := l.LookupDetector("test_ssn")
+	assert.True(t, ok)
+
+	rec := &recorder{}
+	_ = l.NewFilter(rec, l.FilterPII(l.RedactMask, "", "test_ssn")).Log(l.LevelInfo, "ssn", "078-05-1120")
+	assert.Equal(t, []interface{}{l.LevelInfo, "ssn", "***"}, rec.entries[0])
+}
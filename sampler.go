@@ -0,0 +1,199 @@
+package log
+
+import (
+	"hash/fnv"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultSampleTick is the period after which a Sampler starts counting again.
+const DefaultSampleTick = time.Second
+
+const sampleCounters = 4096 // counters per level, messages share them by hash
+
+// SamplerOption is Sampler option.
+type SamplerOption func(*Sampler)
+
+// SampleTick sets the period after which counting starts again.
+func SampleTick(d time.Duration) SamplerOption {
+	return func(s *Sampler) {
+		if d > 0 {
+			s.tick = d
+		}
+	}
+}
+
+// SampleAll samples every level not set with SampleLevel: per tick, the
+// first entries of a message are logged, then every thereafter-th one, none
+// when thereafter is 0.
+func SampleAll(first, thereafter int) SamplerOption {
+	return func(s *Sampler) {
+		for level := LevelTrace; level <= LevelFatal; level++ {
+			if _, ok := s.set[level]; !ok {
+				s.levels[level] = newSampling(first, thereafter)
+			}
+		}
+	}
+}
+
+// SampleLevel samples level as SampleAll does.
+func SampleLevel(level Level, first, thereafter int) SamplerOption {
+	return func(s *Sampler) {
+		s.levels[level] = newSampling(first, thereafter)
+		s.set[level] = struct{}{}
+	}
+}
+
+// SampleMessageKey sets the key of the message entries are counted by,
+// MessageKey by default.
+func SampleMessageKey(key string) SamplerOption {
+	return func(s *Sampler) {
+		s.msgKey = key
+	}
+}
+
+// SampleStatsTo counts into stats, e.g. to share counters between samplers.
+func SampleStatsTo(stats *SampleStats) SamplerOption {
+	return func(s *Sampler) {
+		if stats != nil {
+			s.stats = stats
+		}
+	}
+}
+
+// SampleStats counts the entries a Sampler logged and dropped.
+type SampleStats struct {
+	sampled uint64
+	dropped uint64
+}
+
+// Sampled returns the number of entries subject to sampling that were logged.
+func (s *SampleStats) Sampled() uint64 {
+	return atomic.LoadUint64(&s.sampled)
+}
+
+// Dropped returns the number of entries dropped by sampling.
+func (s *SampleStats) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Sampler is a Logger capping the entries of the same message and level in
+// the style of zap's sampler, whatever the backend of the wrapped Logger.
+// Levels not configured are not sampled.
+type Sampler struct {
+	logger Logger
+	tick   time.Duration
+	levels map[Level]*sampling
+	set    map[Level]struct{}
+	stats  *SampleStats
+	msgKey string
+}
+
+// NewSampler returns a Sampler wrapping logger.
+func NewSampler(logger Logger, opts ...SamplerOption) *Sampler {
+	s := &Sampler{
+		logger: logger,
+		tick:   DefaultSampleTick,
+		levels: make(map[Level]*sampling),
+		set:    make(map[Level]struct{}),
+		stats:  &SampleStats{},
+		msgKey: MessageKey,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Log passes the entry on unless sampling drops it.
+func (s *Sampler) Log(level Level, keyvals ...interface{}) error {
+	return s.log(level, false, keyvals)
+}
+
+// ForceLog is Log whatever the level of the wrapped Logger.
+func (s *Sampler) ForceLog(level Level, keyvals ...interface{}) error {
+	return s.log(level, true, keyvals)
+}
+
+func (s *Sampler) log(level Level, force bool, keyvals []interface{}) error {
+	sp, ok := s.levels[level]
+	if !ok {
+		return logTo(s.logger, force, level, keyvals)
+	}
+	keyvals = NormalizeKeyvals(keyvals)
+	var msg string
+	for i := 0; i < len(keyvals); i += 2 {
+		if keyvals[i] == s.msgKey {
+			v := keyvals[i+1]
+			if f, ok := v.(Field); ok {
+				v = f.Value()
+			}
+			msg, _ = v.(string)
+			break
+		}
+	}
+	n := sp.counter(msg).incCheckReset(time.Now(), s.tick)
+	if n > sp.first && (sp.thereafter == 0 || (n-sp.first)%sp.thereafter != 0) {
+		atomic.AddUint64(&s.stats.dropped, 1)
+		return nil
+	}
+	atomic.AddUint64(&s.stats.sampled, 1)
+	return logTo(s.logger, force, level, keyvals)
+}
+
+// SetLevel sets the level of the wrapped Logger.
+func (s *Sampler) SetLevel(level string) {
+	s.logger.SetLevel(level)
+}
+
+// Enabled reports whether the wrapped Logger is enabled for level.
+func (s *Sampler) Enabled(level Level) bool {
+	return s.logger.Enabled(level)
+}
+
+// Stats returns the counters of s.
+func (s *Sampler) Stats() *SampleStats {
+	return s.stats
+}
+
+type sampling struct {
+	first      uint64
+	thereafter uint64
+	counters   [sampleCounters]counter
+}
+
+func newSampling(first, thereafter int) *sampling {
+	if first < 0 {
+		first = 0
+	}
+	if thereafter < 0 {
+		thereafter = 0
+	}
+	return &sampling{first: uint64(first), thereafter: uint64(thereafter)}
+}
+
+func (s *sampling) counter(msg string) *counter {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(msg))
+	return &s.counters[h.Sum32()%sampleCounters]
+}
+
+type counter struct {
+	resetAt int64 // unix nano, first so it is 64-bit aligned
+	count   uint64
+}
+
+// incCheckReset counts one entry at t and returns the count of the tick.
+func (c *counter) incCheckReset(t time.Time, tick time.Duration) uint64 {
+	now := t.UnixNano()
+	resetAt := atomic.LoadInt64(&c.resetAt)
+	if resetAt > now {
+		return atomic.AddUint64(&c.count, 1)
+	}
+	atomic.StoreUint64(&c.count, 1)
+	if !atomic.CompareAndSwapInt64(&c.resetAt, resetAt, now+tick.Nanoseconds()) {
+		// another entry started the tick first
+		return atomic.AddUint64(&c.count, 1)
+	}
+	return 1
+}
@@ -0,0 +1,47 @@
+package log_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	l "github.com/ysk229/go-logs"
+)
+
+func TestSampler(t *testing.T) {
+	rec := &recorder{}
+	s := l.NewSampler(rec, l.SampleTick(time.Hour), l.SampleLevel(l.LevelInfo, 2, 3))
+	for i := 0; i < 10; i++ {
+		_ = s.Log(l.LevelInfo, "msg", "hot", "i", i)
+	}
+	_ = s.Log(l.LevelInfo, "msg", "other")
+	_ = s.Log(l.LevelWarn, "msg", "hot")
+
+	var got []interface{}
+	for _, e := range rec.entries {
+		if len(e) == 5 {
+			got = append(got, e[4])
+		}
+	}
+	assert.Equal(t, []interface{}{0, 1, 4, 7}, got)
+	assert.Len(t, rec.entries, 6)
+	assert.Equal(t, uint64(5), s.Stats().Sampled())
+	assert.Equal(t, uint64(6), s.Stats().Dropped())
+}
+
+func TestSamplerTick(t *testing.T) {
+	rec := &recorder{}
+	stats := &l.SampleStats{}
+	s := l.NewSampler(rec, l.SampleTick(20*time.Millisecond), l.SampleAll(1, 0), l.SampleLevel(l.LevelError, 0, 0), l.SampleStatsTo(stats))
+	_ = s.Log(l.LevelDebug, "msg", "hot")
+	_ = s.Log(l.LevelDebug, "msg", "hot")
+	_ = s.Log(l.LevelError, "msg", "hot")
+	assert.Len(t, rec.entries, 1)
+	time.Sleep(30 * time.Millisecond)
+	_ = s.Log(l.LevelDebug, "msg", "hot")
+	assert.Len(t, rec.entries, 2)
+	assert.Equal(t, uint64(2), stats.Sampled())
+	assert.Equal(t, uint64(2), stats.Dropped())
+	assert.Same(t, stats, s.Stats())
+}
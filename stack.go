@@ -0,0 +1,153 @@
+package log
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// StackKey is the key of the stack trace added by a StackTracer.
+const StackKey = "stack"
+
+// DefaultStackDepth is how many frames a StackTracer keeps by default.
+const DefaultStackDepth = 32
+
+// internalPkg is the import path of this module, whose frames are hidden.
+var internalPkg = reflect.TypeOf(StackTracer{}).PkgPath()
+
+// Stack is a stack trace, each frame printed as "function\n\tfile:line" the
+// way github.com/pkg/errors prints them. JSON formats log it as a string, text
+// formats print it as an indented block below the entry (see Text).
+type Stack string
+
+// Text returns the indented block text formats print after the entry line,
+// e.g. for key "stack":
+//
+//	stack:
+//	    main.main
+//	    	/app/main.go:12
+func (s Stack) Text(key string) string {
+	var b strings.Builder
+	writeStack(&b, key, string(s), "    ")
+	return b.String()
+}
+
+func writeStack(b *strings.Builder, key, stack, indent string) {
+	fmt.Fprintf(b, "\n%s%s:", indent, key)
+	for _, line := range strings.Split(stack, "\n") {
+		b.WriteString("\n" + indent + "    " + line)
+	}
+}
+
+// StackOption is StackTracer option.
+type StackOption func(*StackTracer)
+
+// StackLevel sets the minimum level of the entries getting a stack trace,
+// LevelError by default.
+func StackLevel(level Level) StackOption {
+	return func(s *StackTracer) {
+		s.level = level
+	}
+}
+
+// StackDepth sets how many frames are kept.
+func StackDepth(n int) StackOption {
+	return func(s *StackTracer) {
+		if n > 0 {
+			s.depth = n
+		}
+	}
+}
+
+// StackInternal keeps the frames of the runtime and of go-logs, hidden by
+// default.
+func StackInternal(keep bool) StackOption {
+	return func(s *StackTracer) {
+		s.internal = keep
+	}
+}
+
+// StackTracer is a Logger adding the stack trace of the caller under StackKey
+// to the entries of its level and above.
+type StackTracer struct {
+	logger   Logger
+	level    Level
+	depth    int
+	internal bool
+}
+
+// NewStackTracer returns a StackTracer wrapping logger.
+func NewStackTracer(logger Logger, opts ...StackOption) *StackTracer {
+	s := &StackTracer{
+		logger: logger,
+		level:  LevelError,
+		depth:  DefaultStackDepth,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Log passes the entry on, with a stack trace from its level on.
+func (s *StackTracer) Log(level Level, keyvals ...interface{}) error {
+	return s.log(level, false, keyvals)
+}
+
+// ForceLog is Log whatever the level of the wrapped Logger.
+func (s *StackTracer) ForceLog(level Level, keyvals ...interface{}) error {
+	return s.log(level, true, keyvals)
+}
+
+func (s *StackTracer) log(level Level, force bool, keyvals []interface{}) error {
+	if level < s.level {
+		return logTo(s.logger, force, level, keyvals)
+	}
+	kvs := make([]interface{}, 0, len(keyvals)+2)
+	kvs = append(kvs, keyvals...)
+	kvs = append(kvs, StackKey, s.stack())
+	return logTo(s.logger, force, level, kvs)
+}
+
+// SetLevel sets the level of the wrapped Logger.
+func (s *StackTracer) SetLevel(level string) {
+	s.logger.SetLevel(level)
+}
+
+// Enabled reports whether the wrapped Logger is enabled for level.
+func (s *StackTracer) Enabled(level Level) bool {
+	return s.logger.Enabled(level)
+}
+
+// stack returns up to depth frames of the calling goroutine, without the
+// internal ones unless they are kept.
+func (s *StackTracer) stack() Stack {
+	pcs := make([]uintptr, s.depth+64) // room for the hidden frames
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	var b strings.Builder
+	for n := 0; n < s.depth; {
+		f, more := frames.Next()
+		if s.internal || !internalFrame(f) {
+			if n > 0 {
+				b.WriteByte('\n')
+			}
+			fmt.Fprintf(&b, "%s\n\t%s:%d", f.Function, f.File, f.Line)
+			n++
+		}
+		if !more {
+			break
+		}
+	}
+	return Stack(b.String())
+}
+
+// internalFrame reports whether f belongs to the runtime or go-logs, out of
+// its tests.
+func internalFrame(f runtime.Frame) bool {
+	if strings.HasPrefix(f.Function, "runtime.") {
+		return true
+	}
+	return (strings.HasPrefix(f.Function, internalPkg+".") || strings.HasPrefix(f.Function, internalPkg+"/")) &&
+		!strings.HasSuffix(f.File, "_test.go")
+}
@@ -0,0 +1,33 @@
+package log_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	l "github.com/ysk229/go-logs"
+)
+
+func TestStackTracer(t *testing.T) {
+	rec := &recorder{}
+	logger := l.With(l.NewStackTracer(rec, l.StackLevel(l.LevelWarn)), "svc", "api")
+	_ = logger.Log(l.LevelInfo, "msg", "no stack")
+	_ = logger.Log(l.LevelWarn, "msg", "stack")
+	assert.Equal(t, []interface{}{l.LevelInfo, "svc", "api", "msg", "no stack"}, rec.entries[0])
+	assert.Equal(t, []interface{}{l.LevelWarn, "svc", "api", "msg", "stack", l.StackKey}, rec.entries[1][:6])
+
+	// the caller comes first, the frames of go-logs and the runtime are hidden
+	stack := string(rec.entries[1][6].(l.Stack))
+	assert.True(t, strings.HasPrefix(stack, "github.com/ysk229/go-logs_test.TestStackTracer\n\t"), stack)
+	assert.Contains(t, stack, "stack_test.go:")
+	assert.NotContains(t, stack, "github.com/ysk229/go-logs.")
+	assert.NotContains(t, stack, "runtime.")
+
+	_ = l.With(l.NewStackTracer(rec, l.StackDepth(1), l.StackInternal(true))).Log(l.LevelError, "msg", "internal")
+	stack = string(rec.entries[2][4].(l.Stack))
+	assert.Equal(t, 1, strings.Count(stack, "\n\t"), stack)
+	assert.True(t, strings.HasPrefix(stack, "github.com/ysk229/go-logs.(*StackTracer).Log\n\t"), stack)
+
+	assert.Equal(t, "\n    stack:\n        main.main\n        \t/app/main.go:12", l.Stack("main.main\n\t/app/main.go:12").Text("stack"))
+}
@@ -0,0 +1,85 @@
+package log
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// DefaultTimeLayout is the layout of the timestamps of entries by default.
+const DefaultTimeLayout = "2006-01-02.15:04:05.000000"
+
+// Presets of TimeFormat.Layout, any other layout is a Go layout.
+const (
+	TimeRFC3339Nano = "RFC3339Nano" // time.RFC3339Nano
+	TimeUnix        = "unix"        // seconds since the epoch
+	TimeUnixMilli   = "unix_ms"     // milliseconds since the epoch
+	TimeUnixNano    = "unix_nano"   // nanoseconds since the epoch
+)
+
+// Clock tells the time of entries, e.g. a fixed time in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc is a Clock calling f.
+type ClockFunc func() time.Time
+
+// Now returns f().
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// TimeFormat is how entries are timestamped. The zero TimeFormat logs the
+// local time in DefaultTimeLayout.
+type TimeFormat struct {
+	Layout   string         // Go layout or preset, DefaultTimeLayout when empty
+	Location *time.Location // time zone, nil keeps the one of the time
+	Clock    Clock          // nil reads time.Now
+}
+
+// Now returns the time of the Clock.
+func (f TimeFormat) Now() time.Time {
+	if f.Clock != nil {
+		return f.Clock.Now()
+	}
+	return time.Now()
+}
+
+// Value returns t as logged: an int64 for the unix presets, else a string.
+func (f TimeFormat) Value(t time.Time) interface{} {
+	if f.Location != nil {
+		t = t.In(f.Location)
+	}
+	switch f.Layout {
+	case "":
+		return t.Format(DefaultTimeLayout)
+	case TimeRFC3339Nano:
+		return t.Format(time.RFC3339Nano)
+	case TimeUnix:
+		return t.Unix()
+	case TimeUnixMilli:
+		return t.UnixMilli()
+	case TimeUnixNano:
+		return t.UnixNano()
+	}
+	return t.Format(f.Layout)
+}
+
+// Format returns Value as a string, for text formats.
+func (f TimeFormat) Format(t time.Time) string {
+	switch v := f.Value(t).(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case string:
+		return v
+	}
+	return ""
+}
+
+// Valuer returns a Valuer of the current time.
+func (f TimeFormat) Valuer() Valuer {
+	return func(context.Context) interface{} {
+		return f.Value(f.Now())
+	}
+}
@@ -0,0 +1,38 @@
+package log_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	l "github.com/ysk229/go-logs"
+)
+
+func TestTimeFormat(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 600000000, time.FixedZone("X", 3600))
+	tests := map[string]struct {
+		format l.TimeFormat
+		want   interface{}
+	}{
+		"default":     {l.TimeFormat{}, "2024-01-02.03:04:05.600000"},
+		"layout":      {l.TimeFormat{Layout: time.Kitchen}, "3:04AM"},
+		"RFC3339Nano": {l.TimeFormat{Layout: l.TimeRFC3339Nano}, "2024-01-02T03:04:05.6+01:00"},
+		"utc":         {l.TimeFormat{Layout: l.TimeRFC3339Nano, Location: time.UTC}, "2024-01-02T02:04:05.6Z"},
+		"unix":        {l.TimeFormat{Layout: l.TimeUnix}, int64(1704161045)},
+		"unix_ms":     {l.TimeFormat{Layout: l.TimeUnixMilli}, int64(1704161045600)},
+		"unix_nano":   {l.TimeFormat{Layout: l.TimeUnixNano}, int64(1704161045600000000)},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.format.Value(at))
+		})
+	}
+	assert.Equal(t, "1704161045", l.TimeFormat{Layout: l.TimeUnix}.Format(at))
+
+	clock := l.ClockFunc(func() time.Time { return at })
+	assert.Equal(t, int64(1704161045), l.TimeFormat{Layout: l.TimeUnix, Clock: clock}.Valuer()(context.Background()))
+	assert.WithinDuration(t, time.Now(), l.TimeFormat{}.Now(), time.Minute)
+	assert.IsType(t, int64(0), l.Timestamp(l.TimeUnixNano)(context.Background()))
+}
@@ -4,62 +4,117 @@ import (
 	"context"
 	"runtime"
 	"strconv"
-	"strings"
-	"time"
 )
 
 var (
-	// DefaultCaller is a Valuer that returns the file and line.
-	DefaultCaller = Caller(3)
+	// DefaultCaller is a Valuer that returns the file and line of the caller of
+	// the logger, see CallerResolver.
+	DefaultCaller = CallerResolver()
 
 	// DefaultTimestamp is a Valuer that returns the current wallclock time.
-	DefaultTimestamp = Timestamp("2006-01-02.15:04:05.000000")
+	DefaultTimestamp = Timestamp(DefaultTimeLayout)
 )
 
 // Valuer is returns a log value.
 type Valuer func(ctx context.Context) interface{}
 
+// LogValuer is a value computed only for the entries that are logged, e.g.
+// Infow("state", log.Lazy(func() interface{} { return dump() })). Like
+// Valuers, LogValuers are resolved in the fields of With and of the entry.
+type LogValuer interface {
+	LogValue() interface{}
+}
+
+// Omit is returned by a Valuer or a LogValuer to leave its field out of the
+// entry, e.g. a trace id when the context has no span.
+var Omit interface{} = omit{}
+
+type omit struct{}
+
+// Lazy returns a LogValuer calling f.
+func Lazy(f func() interface{}) LogValuer {
+	return lazy(f)
+}
+
+type lazy func() interface{}
+
+func (f lazy) LogValue() interface{} {
+	return f()
+}
+
 // Value return the function value.
 func Value(ctx context.Context, v interface{}) interface{} {
-	if v, ok := v.(Valuer); ok {
+	switch v := v.(type) {
+	case Valuer:
 		return v(ctx)
+	case LogValuer:
+		return v.LogValue()
+	case *AnyField:
+		if isValuer(v.Val) {
+			return &AnyField{v.fieldKey, Value(ctx, v.Val)}
+		}
 	}
 	return v
 }
 
-// Caller returns a Valuer that returns a pkg/file:line description of the caller.
+// Caller returns a Valuer that returns a pkg/file:line description of the
+// frame depth levels up from the one resolving it. CallerResolver finds the
+// caller whatever the depth.
 func Caller(depth int) Valuer {
 	return func(context.Context) interface{} {
 		_, file, line, _ := runtime.Caller(depth)
-		idx := strings.LastIndexByte(file, '/')
-		idx2 := strings.LastIndexByte(file[:idx], '/')
-		if idx2 == -1 {
-			return file[idx+1:] + ":" + strconv.Itoa(line)
-		}
-		return file[idx2+1:] + ":" + strconv.Itoa(line)
+		return shortFile(file) + ":" + strconv.Itoa(line)
 	}
 }
 
-// Timestamp returns a timestamp Valuer with a custom time format.
+// Timestamp returns a timestamp Valuer with a custom time format, a Go
+// layout or a preset of TimeFormat.
 func Timestamp(layout string) Valuer {
-	return func(context.Context) interface{} {
-		return time.Now().Format(layout)
-	}
+	return TimeFormat{Layout: layout}.Valuer()
 }
 
 func bindValues(ctx context.Context, keyvals []interface{}) {
 	for i := 1; i < len(keyvals); i += 2 {
-		if v, ok := keyvals[i].(Valuer); ok {
+		switch v := keyvals[i].(type) {
+		case Valuer: // called here, the depth of Caller counts from this frame
 			keyvals[i] = v(ctx)
+		case LogValuer:
+			keyvals[i] = v.LogValue()
+		case *AnyField:
+			if isValuer(v) {
+				keyvals[i] = Value(ctx, v)
+			}
+		}
+	}
+}
+
+// omitValues removes from kvs, pairs, the fields valued Omit, in place.
+func omitValues(kvs []interface{}) []interface{} {
+	out := kvs[:0]
+	for i := 0; i < len(kvs); i += 2 {
+		if _, ok := kvs[i+1].(omit); !ok {
+			out = append(out, kvs[i], kvs[i+1])
 		}
 	}
+	return out
 }
 
 func containsValuer(keyvals []interface{}) bool {
 	for i := 1; i < len(keyvals); i += 2 {
-		if _, ok := keyvals[i].(Valuer); ok {
+		if isValuer(keyvals[i]) {
 			return true
 		}
 	}
 	return false
 }
+
+// isValuer reports whether v is resolved by Value.
+func isValuer(v interface{}) bool {
+	switch v := v.(type) {
+	case Valuer, LogValuer:
+		return true
+	case *AnyField:
+		return isValuer(v.Val)
+	}
+	return false
+}
@@ -5,6 +5,8 @@ import (
 	"log"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	l "github.com/ysk229/go-logs"
 	"github.com/ysk229/go-logs/contrib/std"
 )
@@ -32,3 +34,45 @@ func TestValue(t *testing.T) {
 		t.Errorf("Value() = %v, want %v", res, 3)
 	}
 }
+
+func TestLazy(t *testing.T) {
+	rec := &recorder{}
+	calls := 0
+	lazy := l.Lazy(func() interface{} {
+		calls++
+		return "expensive"
+	})
+	valuer := l.Valuer(func(context.Context) interface{} {
+		calls++
+		return 42
+	})
+	logger := l.With(rec, "prefix", lazy)
+	rec.SetLevel("info")
+
+	_ = logger.Log(l.LevelDebug, "call", lazy, "valuer", valuer, "field", l.Any("any", valuer))
+	assert.False(t, logger.Enabled(l.LevelDebug))
+	assert.Empty(t, rec.entries)
+	assert.Zero(t, calls, "values of disabled entries must not be resolved")
+
+	_ = logger.Log(l.LevelInfo, "call", lazy, "valuer", valuer, "field", l.Any("any", valuer))
+	assert.True(t, logger.Enabled(l.LevelInfo))
+	assert.Equal(t, []interface{}{l.LevelInfo, "prefix", "expensive", "call", "expensive", "valuer", 42, "field", l.Any("any", 42)}, rec.entries[0])
+	assert.Equal(t, 4, calls)
+}
+
+type userKey struct{}
+
+func TestOmit(t *testing.T) {
+	rec := &recorder{}
+	user := func(ctx context.Context) interface{} {
+		if u, ok := ctx.Value(userKey{}).(string); ok {
+			return u
+		}
+		return l.Omit
+	}
+	logger := l.With(rec, "user", l.Valuer(user))
+	_ = logger.Log(l.LevelInfo, "msg", "anonymous", "state", l.Lazy(func() interface{} { return l.Omit }))
+	_ = l.WithContext(context.WithValue(context.Background(), userKey{}, "bob"), logger).Log(l.LevelInfo, "msg", "known")
+	assert.Equal(t, []interface{}{l.LevelInfo, "msg", "anonymous"}, rec.entries[0])
+	assert.Equal(t, []interface{}{l.LevelInfo, "user", "bob", "msg", "known"}, rec.entries[1])
+}